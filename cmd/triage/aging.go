@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/aging"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newAgingCmd() *cobra.Command {
+	var staleDays int
+	var format string
+	var out string
+	cmd := &cobra.Command{
+		Use:          "aging",
+		Short:        "Aging report: open PRs by age and label, first maintainer response, stale needs-human PRs",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			rep, err := aging.Build(cfg, tax, staleDays, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+
+			var body []byte
+			switch format {
+			case "md":
+				body = []byte(aging.Markdown(rep))
+			case "json":
+				body, err = aging.JSON(rep)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid --format %q (want md|json)", format)
+			}
+			if out == "" {
+				_, err := os.Stdout.Write(body)
+				return err
+			}
+			if err := storage.WriteFileAtomic(out, body, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "wrote %s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&staleDays, "stale-days", 14, "Flag PRs with a needs_human label and no maintainer comment or review for this many days")
+	cmd.Flags().StringVar(&format, "format", "md", "Output format: md|json")
+	cmd.Flags().StringVar(&out, "out", "", "Write the report to a file instead of stdout")
+	return cmd
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/spf13/cobra"
+)
+
+func newCardsCmd() *cobra.Command {
+	var cardDir string
+	var byRubric bool
+	var migrate bool
+	cmd := &cobra.Command{
+		Use:          "cards",
+		Short:        "Report on existing cards",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			if !byRubric && !migrate {
+				return errors.New("choose one: --by-rubric or --migrate")
+			}
+			if cardDir == "" {
+				cardDir = cfg.MapDir
+			} else if !filepath.IsAbs(cardDir) {
+				cardDir = filepath.Join(cfg.DataRoot, cardDir)
+			}
+			if migrate {
+				prs, err := card.Migrate(cardDir)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stdout, "migrated %d cards in %s\n", len(prs), cardDir)
+				if !byRubric {
+					return nil
+				}
+			}
+			return reportByRubric(cfg, cardDir)
+		},
+	}
+	cmd.Flags().StringVar(&cardDir, "cards", "", "Cards directory (default: <data-root>/triage/map)")
+	cmd.Flags().BoolVar(&migrate, "migrate", false, "Write pr-N.card.json for Markdown cards written before sidecars existed")
+	cmd.Flags().BoolVar(&byRubric, "by-rubric", false, "Count cards per rubric version; cards not on the current rubric need re-mapping")
+	return cmd
+}
+
+func reportByRubric(cfg config.Config, cardDir string) error {
+	records, err := card.ReadDir(cardDir)
+	if err != nil {
+		return err
+	}
+	current := rubric.Stamp(cfg.DataRoot)
+	counts := map[string]int{}
+	stale := []int{}
+	total := 0
+	for _, rec := range records {
+		stamp := rec.Provenance.Rubric
+		if stamp == "" {
+			stamp = "(unstamped)"
+		}
+		counts[stamp]++
+		total++
+		if stamp != current {
+			stale = append(stale, rec.PR)
+		}
+	}
+
+	stamps := make([]string, 0, len(counts))
+	for stamp := range counts {
+		stamps = append(stamps, stamp)
+	}
+	sort.Strings(stamps)
+
+	fmt.Fprintf(os.Stdout, "cards: %d (%s)\n", total, cardDir)
+	fmt.Fprintf(os.Stdout, "current rubric: %s\n", current)
+	for _, stamp := range stamps {
+		marker := ""
+		if stamp == current {
+			marker = " (current)"
+		}
+		fmt.Fprintf(os.Stdout, "- %s: %d%s\n", stamp, counts[stamp], marker)
+	}
+	sort.Ints(stale)
+	fmt.Fprintf(os.Stdout, "needs re-map: %d\n", len(stale))
+	if len(stale) > 0 {
+		prs := make([]string, 0, len(stale))
+		for _, pr := range stale {
+			prs = append(prs, fmt.Sprintf("%d", pr))
+		}
+		fmt.Fprintf(os.Stdout, "  --pr %s\n", strings.Join(prs, ","))
+	}
+	return nil
+}
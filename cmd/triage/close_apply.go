@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/gh"
+	"github.com/joshp123/github-triage/internal/queue"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newCloseApplyCmd() *cobra.Command {
+	var input string
+	var approve string
+	var commentFile string
+	var delay time.Duration
+	cmd := &cobra.Command{
+		Use:          "close-apply",
+		Short:        "Close the PRs in the reviewed close queue (dry run unless --approve <plan-hash>)",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			if input == "" {
+				input = filepath.Join(cfg.TriageDir, "close", "queue.md")
+			} else if !filepath.IsAbs(input) {
+				input = filepath.Join(cfg.DataRoot, input)
+			}
+			if commentFile == "" {
+				commentFile = queue.CommentPath(cfg)
+			}
+			comment := []byte(queue.DefaultComment)
+			if data, err := os.ReadFile(commentFile); err == nil {
+				comment = data
+			} else if !errors.Is(err, os.ErrNotExist) || cmd.Flags().Changed("comment-file") {
+				return fmt.Errorf("read close comment: %w", err)
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			plan, err := queue.BuildPlan(cfg, tax, input, comment)
+			if err != nil {
+				return err
+			}
+			if approve == "" {
+				fmt.Fprint(os.Stdout, queue.RenderPlan(plan))
+				return nil
+			}
+			if approve != plan.Hash {
+				return fmt.Errorf("--approve %s does not match the plan for %s (plan hash %s; the queue or close comment changed); review the new plan without --approve", approve, plan.Path, plan.Hash)
+			}
+
+			closed := 0
+			for _, p := range plan.PRs {
+				if p.Skip != "" {
+					fmt.Fprintf(os.Stderr, "#%d: skipped (%s)\n", p.PR, p.Skip)
+					continue
+				}
+				if closed > 0 && delay > 0 {
+					select {
+					case <-time.After(delay):
+					case <-cmd.Context().Done():
+						return cmd.Context().Err()
+					}
+				}
+				if _, err := gh.Run(cmd.Context(), "pr", "close", strconv.Itoa(p.PR), "--repo", cfg.Repo, "--comment", p.Comment); err != nil {
+					return fmt.Errorf("close #%d (closed %d of %d, stopping): %w", p.PR, closed, plan.Close, err)
+				}
+				closed++
+				if err := queue.RecordApplied(cfg, queue.Applied{Time: time.Now().UTC(), PR: p.PR, Queue: plan.Hash}); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "#%d: closed (%d/%d)\n", p.PR, closed, plan.Close)
+			}
+			fmt.Fprintf(os.Stdout, "closed %d PRs from %s\n", closed, plan.Path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&input, "input", "", "Reviewed close queue (default: <data-root>/triage/close/queue.md)")
+	cmd.Flags().StringVar(&approve, "approve", "", "Plan hash from the dry run; closes the PRs")
+	cmd.Flags().StringVar(&commentFile, "comment-file", "", "Close comment template (default: <data-root>/triage/close/comment.md, else built in)")
+	cmd.Flags().DurationVar(&delay, "delay", 2*time.Second, "Wait between closes (rate limit)")
+	return cmd
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/joshp123/github-triage/internal/config"
 	"github.com/joshp123/github-triage/internal/queue"
+	"github.com/joshp123/github-triage/internal/taxonomy"
 	"github.com/spf13/cobra"
 )
 
@@ -28,7 +29,11 @@ func newCloseQueueCmd() *cobra.Command {
 			} else if !filepath.IsAbs(cardDir) {
 				cardDir = filepath.Join(cfg.DataRoot, cardDir)
 			}
-			queueData, err := queue.BuildCloseQueue(cardDir)
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			queueData, err := queue.BuildCloseQueue(cardDir, tax)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect triage.toml defaults",
+	}
+	cmd.AddCommand(newConfigShowCmd())
+	return cmd
+}
+
+func newConfigShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "show [command...]",
+		Short:        "Print the effective configuration and where each value came from",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			layers, err := loadLayers(root)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stdout, "files:")
+			for _, layer := range layers {
+				state := "missing"
+				if layer.Found {
+					state = "found"
+				}
+				fmt.Fprintf(os.Stdout, "  %s: %s (%s)\n", layer.Name, layer.Path, state)
+			}
+			if cfg, err := config.Load(repoFlag); err == nil {
+				_, source, err := taxonomy.LoadWithSource(cfg.DataRoot)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stdout, "  taxonomy: %s\n", source)
+				fmt.Fprintf(os.Stdout, "  prompts: %s (overrides embedded)\n", cfg.PromptsDir)
+			}
+			fmt.Fprintln(os.Stdout)
+
+			fmt.Fprintln(os.Stdout, "global:")
+			root.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+				printSetting(layers, nil, f)
+			})
+
+			only := map[string]bool{}
+			for _, arg := range args {
+				only[arg] = true
+			}
+			for _, sub := range commandTree(root) {
+				path := commandPath(sub)
+				if len(only) > 0 && !only[strings.Join(path, " ")] && !only[path[0]] {
+					continue
+				}
+				flags := []*pflag.Flag{}
+				sub.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+					flags = append(flags, f)
+				})
+				// Global flags show up again where a command table overrides them.
+				sub.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+					if commandDefault(layers, path, f.Name) {
+						flags = append(flags, f)
+					}
+				})
+				if len(flags) == 0 {
+					continue
+				}
+				fmt.Fprintf(os.Stdout, "\n%s:\n", strings.Join(path, " "))
+				for _, f := range flags {
+					printSetting(layers, path, f)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// printSetting prints one flag's effective value and its source: flag,
+// repo config, user config or default.
+func printSetting(layers []settingsLayer, path []string, f *pflag.Flag) {
+	if f.Name == "help" {
+		return
+	}
+	value, source := f.DefValue, "default"
+	switch {
+	case f.Changed:
+		value, source = f.Value.String(), "flag"
+	case f.Name == "repo":
+		// The repo can only come from the user file; the repo file is
+		// found through it.
+		if v, ok := layers[len(layers)-1].Settings.Default(nil, "repo"); ok {
+			value, source = fmt.Sprint(v), "user config"
+		}
+	default:
+		if v, layer, ok := lookupDefault(layers, path, f.Name); ok {
+			value, source = fmt.Sprint(v), layer.Name
+		}
+	}
+	fmt.Fprintf(os.Stdout, "  %s = %q (%s)\n", f.Name, value, source)
+}
+
+// commandDefault reports whether a command table, rather than the top-level
+// defaults, supplies flag for path.
+func commandDefault(layers []settingsLayer, path []string, flag string) bool {
+	value, layer, ok := lookupDefault(layers, path, flag)
+	if !ok {
+		return false
+	}
+	top, topLayer, ok := lookupDefault(layers, nil, flag)
+	return !ok || topLayer.Path != layer.Path || fmt.Sprint(top) != fmt.Sprint(value)
+}
+
+// commandTree lists the visible commands below root, depth first.
+func commandTree(root *cobra.Command) []*cobra.Command {
+	out := []*cobra.Command{}
+	for _, sub := range root.Commands() {
+		if sub.Hidden || sub.Name() == "help" || sub.Name() == "completion" {
+			continue
+		}
+		out = append(out, sub)
+		out = append(out, commandTree(sub)...)
+	}
+	return out
+}
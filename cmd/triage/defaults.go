@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// settingsLayer is one triage.toml that supplies flag defaults.
+type settingsLayer struct {
+	Name     string
+	Path     string
+	Found    bool
+	Settings config.Settings
+}
+
+// loadLayers returns the triage.toml layers in precedence order: the repo's
+// file in the data root, then the user file. The repo layer is only loaded
+// once the repo is known (it may itself come from the user file).
+func loadLayers(root *cobra.Command) ([]settingsLayer, error) {
+	layers := []settingsLayer{}
+	user := settingsLayer{Name: "user config"}
+	if path, err := config.UserSettingsPath(); err == nil {
+		layer, err := loadLayer(root, user.Name, path)
+		if err != nil {
+			return nil, err
+		}
+		user = layer
+	}
+	if value, ok := user.Settings.Default(nil, "repo"); ok && !root.PersistentFlags().Changed("repo") {
+		if err := setFlag(root.PersistentFlags().Lookup("repo"), value); err != nil {
+			return nil, fmt.Errorf("%s: defaults.repo: %w", user.Path, err)
+		}
+	}
+	if cfg, err := config.Load(repoFlag); err == nil {
+		layer, err := loadLayer(root, "repo config", cfg.SettingsPath)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return append(layers, user), nil
+}
+
+func loadLayer(root *cobra.Command, name string, path string) (settingsLayer, error) {
+	layer := settingsLayer{Name: name, Path: path}
+	if _, err := os.Stat(path); err != nil {
+		return layer, nil
+	}
+	settings, err := config.LoadSettings(path)
+	if err != nil {
+		return layer, err
+	}
+	if err := checkDefaults(root, settings.Defaults, nil, true); err != nil {
+		return layer, fmt.Errorf("%s: %w", path, err)
+	}
+	layer.Found = true
+	layer.Settings = settings
+	return layer, nil
+}
+
+// checkDefaults rejects [defaults] keys that name no flag or command, so a
+// typo fails loudly instead of being ignored. Top-level keys may name a flag
+// of any command.
+func checkDefaults(cmd *cobra.Command, defaults map[string]any, path []string, top bool) error {
+	names := sortedKeys(defaults)
+	for _, key := range names {
+		if table, ok := defaults[key].(map[string]any); ok {
+			sub := subcommand(cmd, key)
+			if sub == nil {
+				return fmt.Errorf("defaults.%s: unknown command", strings.Join(append(path, key), "."))
+			}
+			if err := checkDefaults(sub, table, append(path, key), false); err != nil {
+				return err
+			}
+			continue
+		}
+		known := cmd.Flags().Lookup(key) != nil || cmd.InheritedFlags().Lookup(key) != nil
+		if top {
+			known = anyCommandHasFlag(cmd, key)
+		}
+		if known {
+			continue
+		}
+		return fmt.Errorf("defaults.%s: unknown flag", strings.Join(append(path, key), "."))
+	}
+	return nil
+}
+
+func subcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func anyCommandHasFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if anyCommandHasFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandPath is cmd's path below the root, e.g. ["rubric", "init"].
+func commandPath(cmd *cobra.Command) []string {
+	path := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		path = append([]string{c.Name()}, path...)
+	}
+	return path
+}
+
+// lookupDefault finds the first layer with a default for flag under path.
+func lookupDefault(layers []settingsLayer, path []string, flag string) (any, settingsLayer, bool) {
+	for _, layer := range layers {
+		if value, ok := layer.Settings.Default(path, flag); ok {
+			return value, layer, true
+		}
+	}
+	return nil, settingsLayer{}, false
+}
+
+// applyDefaults fills every flag the user did not set from the triage.toml
+// layers. Explicit flags always win.
+func applyDefaults(cmd *cobra.Command) error {
+	layers, err := loadLayers(cmd.Root())
+	if err != nil {
+		return err
+	}
+	path := commandPath(cmd)
+	var applyErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if applyErr != nil || f.Changed || f.Name == "repo" {
+			return
+		}
+		value, layer, ok := lookupDefault(layers, path, f.Name)
+		if !ok {
+			return
+		}
+		if err := setFlag(f, value); err != nil {
+			applyErr = fmt.Errorf("%s: defaults %s: %w", layer.Path, f.Name, err)
+		}
+	})
+	return applyErr
+}
+
+// setFlag sets f from a TOML value without marking it changed.
+func setFlag(f *pflag.Flag, value any) error {
+	if items, ok := value.([]any); ok {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			values = append(values, fmt.Sprint(item))
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			return slice.Replace(values)
+		}
+		return f.Value.Set(strings.Join(values, ","))
+	}
+	return f.Value.Set(fmt.Sprint(value))
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
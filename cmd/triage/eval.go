@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/eval"
+	"github.com/spf13/cobra"
+)
+
+func newEvalCmd() *cobra.Command {
+	var goldPath string
+	var stage string
+	var runID string
+	var baseline string
+	var cardDir string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:          "eval",
+		Short:        "Score map/sweep labels against a gold-labelled PR set",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			if err := cfg.EnsureDirs(); err != nil {
+				return err
+			}
+			if stage != "map" && stage != "sweep" {
+				return fmt.Errorf("invalid --stage %q (want map|sweep)", stage)
+			}
+			gold, err := eval.LoadGold(goldPath)
+			if err != nil {
+				return err
+			}
+			if runID == "" {
+				runID = time.Now().UTC().Format("20060102T150405Z")
+			}
+			runDir := filepath.Join(cfg.EvalDir, runID)
+
+			model := ""
+			if cardDir == "" {
+				cardDir = filepath.Join(runDir, "cards")
+				ensureSelfInPath()
+				runner, err := newRunner(cfg)
+				if err != nil {
+					return err
+				}
+				model = runner.Provider + "/" + runner.Model
+				runner.RunID = runID
+				relCardDir, err := filepath.Rel(cfg.DataRoot, cardDir)
+				if err != nil {
+					return err
+				}
+				if err := runner.Classify(cmd.Context(), cfg, stage, eval.PRNumbers(gold), concurrencyFlag, timeout, relCardDir); err != nil {
+					return err
+				}
+			} else if !filepath.IsAbs(cardDir) {
+				cardDir = filepath.Join(cfg.DataRoot, cardDir)
+			}
+
+			report := eval.Score(gold, cardDir)
+			report.RunID = runID
+			report.Stage = stage
+			report.Model = model
+
+			if baseline == "" {
+				baseline, err = eval.LatestRun(cfg.EvalDir, report)
+				if err != nil {
+					return err
+				}
+			} else if !filepath.IsAbs(baseline) && filepath.Dir(baseline) == "." {
+				baseline = filepath.Join(cfg.EvalDir, baseline)
+			}
+			if baseline != "" {
+				previous, err := eval.LoadReport(baseline)
+				if err != nil {
+					return err
+				}
+				if err := eval.Compare(&report, previous); err != nil {
+					return err
+				}
+			}
+
+			if err := eval.WriteReport(runDir, report); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "%s", eval.RenderReport(report))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&goldPath, "gold", "", "Gold labels (JSONL: {\"pr\":123,\"label\":\"slop\",\"close_ready\":true})")
+	cmd.Flags().StringVar(&stage, "stage", "map", "Stage to evaluate: map|sweep")
+	cmd.Flags().StringVar(&runID, "run-id", "", "Eval run id (default: UTC timestamp)")
+	cmd.Flags().StringVar(&baseline, "baseline", "", "Previous eval run id or dir to compare against (default: the latest run of the same stage and gold set)")
+	cmd.Flags().StringVar(&cardDir, "cards", "", "Score an existing cards directory instead of running the model")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Per-PR timeout (e.g. 2m, 30s)")
+	_ = cmd.MarkFlagRequired("gold")
+	return cmd
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/inventory"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newInventoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Browse and compare archived inventory snapshots",
+	}
+	cmd.AddCommand(newInventoryListCmd())
+	cmd.AddCommand(newInventoryDiffCmd())
+	return cmd
+}
+
+func newInventoryListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List archived snapshots (triage/reduce/<date>.json)",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			dates, err := inventory.ListSnapshots(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			if len(dates) == 0 {
+				fmt.Fprintln(os.Stdout, "no snapshots (run `triage reduce`)")
+				return nil
+			}
+			for _, date := range dates {
+				snap, err := inventory.LoadSnapshot(cfg.DataRoot, date)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stdout, "%s\t%d items\n", date, len(snap.Items))
+			}
+			return nil
+		},
+	}
+}
+
+func newInventoryDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [a] [b]",
+		Short: "Show PRs new, closed/merged or relabeled between two snapshots, with count deltas",
+		Long: "Snapshots are named by date (2026-01-31), `current`, or a path to a snapshot .json.\n" +
+			"With no arguments, compares the two latest archived snapshots; with one, compares it to `current`.",
+		Args:         cobra.MaximumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+
+			names := args
+			switch len(args) {
+			case 0:
+				dates, err := inventory.ListSnapshots(cfg.DataRoot)
+				if err != nil {
+					return err
+				}
+				if len(dates) < 2 {
+					return errors.New("need two archived snapshots to compare (name them explicitly, or see `triage inventory list`)")
+				}
+				names = dates[len(dates)-2:]
+			case 1:
+				names = []string{args[0], "current"}
+			}
+
+			from, err := inventory.LoadSnapshot(cfg.DataRoot, names[0])
+			if err != nil {
+				return err
+			}
+			to, err := inventory.LoadSnapshot(cfg.DataRoot, names[1])
+			if err != nil {
+				return err
+			}
+			diff, err := inventory.Compare(cfg.DataRoot, from, to)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(os.Stdout, inventory.RenderDiff(tax, diff))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newLintCardsCmd() *cobra.Command {
+	var stage string
+	var cardDir string
+	var quarantine bool
+	cmd := &cobra.Command{
+		Use:          "lint-cards",
+		Short:        "Check cards against the schema and taxonomy; optionally quarantine invalid ones",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+
+			stages := []string{"map", "sweep"}
+			switch stage {
+			case "":
+			case "map", "sweep":
+				stages = []string{stage}
+			default:
+				return fmt.Errorf("invalid --stage %q (want map|sweep)", stage)
+			}
+			if cardDir != "" && len(stages) != 1 {
+				return errors.New("--cards needs --stage")
+			}
+
+			bad := 0
+			for _, s := range stages {
+				dir := filepath.Join(cfg.TriageDir, s)
+				if cardDir != "" {
+					dir = cardDir
+					if !filepath.IsAbs(dir) {
+						dir = filepath.Join(cfg.DataRoot, dir)
+					}
+				}
+				if _, err := os.Stat(dir); err != nil && cardDir == "" {
+					continue
+				}
+				total, problems, err := card.LintDir(dir, tax, s)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stdout, "%s: %d cards, %d with issues (%s)\n", s, total, len(problems), dir)
+				for _, p := range problems {
+					fmt.Fprintf(os.Stdout, "- #%d\n", p.PR)
+					for _, issue := range p.Issues {
+						fmt.Fprintf(os.Stdout, "  - %s\n", issue)
+					}
+				}
+				if quarantine && len(problems) > 0 {
+					qdir := filepath.Join(cfg.TriageDir, "quarantine", s)
+					prs := make([]string, 0, len(problems))
+					for _, p := range problems {
+						if err := card.Quarantine(qdir, p); err != nil {
+							return err
+						}
+						prs = append(prs, fmt.Sprintf("%d", p.PR))
+					}
+					fmt.Fprintf(os.Stdout, "quarantined %d cards to %s; re-run: triage %s --pr %s\n", len(problems), qdir, s, strings.Join(prs, ","))
+					continue
+				}
+				bad += len(problems)
+			}
+			if bad > 0 {
+				return fmt.Errorf("%d cards with issues (use --quarantine to move them aside)", bad)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&stage, "stage", "", "Card stage to lint: map|sweep (default: both)")
+	cmd.Flags().StringVar(&cardDir, "cards", "", "Cards directory (default: <data-root>/triage/<stage>; needs --stage)")
+	cmd.Flags().BoolVar(&quarantine, "quarantine", false, "Move invalid cards to <data-root>/triage/quarantine/<stage>/ so the next run regenerates them")
+	return cmd
+}
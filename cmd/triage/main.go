@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/joshp123/github-triage/internal/config"
 	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/llm"
+	"github.com/joshp123/github-triage/internal/metrics"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +15,11 @@ var (
 	repoFlag        string
 	modelFlag       string
 	concurrencyFlag int
+	backendFlag     string
+	replayDirFlag   string
+	baseURLFlag     string
+	promptDirFlag   string
+	thinkingFlag    string
 )
 
 func main() {
@@ -19,32 +27,73 @@ func main() {
 		Use:          "triage",
 		Short:        "github-triage CLI",
 		SilenceUsage: true,
+		// Flag defaults come from triage.toml (repo, then user); explicit
+		// flags win.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return applyDefaults(cmd)
+		},
 	}
 
 	root.PersistentFlags().StringVar(&repoFlag, "repo", "openclaw/openclaw", "GitHub repo (org/name)")
 	root.PersistentFlags().StringVar(&modelFlag, "model", "openai-codex/gpt-5.2", "LLM model or provider/model (e.g. openai-codex/gpt-5.2)")
 	root.PersistentFlags().IntVar(&concurrencyFlag, "concurrency", 8, "LLM concurrency (reserved)")
+	root.PersistentFlags().StringVar(&backendFlag, "backend", llm.DefaultBackend, "LLM backend: pi|openai|replay (pi only in -tags pi builds, where it is the default)")
+	root.PersistentFlags().StringVar(&replayDirFlag, "replay-dir", "", "Recorded sessions for --backend replay")
+	root.PersistentFlags().StringVar(&promptDirFlag, "prompt-dir", "", "Prompt override dir (checked before <data-root>/triage/prompts and the embedded prompts)")
+	root.PersistentFlags().StringVar(&thinkingFlag, "thinking", "", "Thinking level low|medium|high (default per stage: map/reduce/discover high, sweep/scan-injection low)")
+	root.PersistentFlags().StringVar(&baseURLFlag, "openai-base-url", "", "Chat completions base URL for --backend openai (default: $OPENAI_BASE_URL or https://api.openai.com/v1)")
 
 	root.AddCommand(newDiscoverCmd())
 	root.AddCommand(newRunCmd())
+	root.AddCommand(newRubricCmd())
+	root.AddCommand(newScanInjectionCmd())
 	root.AddCommand(newMapCmd())
 	root.AddCommand(newSweepCmd())
 	root.AddCommand(newCloseQueueCmd())
+	root.AddCommand(newCloseApplyCmd())
+	root.AddCommand(newReportCmd())
+	root.AddCommand(newAgingCmd())
+	root.AddCommand(newTrendsCmd())
+	root.AddCommand(newReleaseNotesCmd())
+	root.AddCommand(newCardsCmd())
+	root.AddCommand(newLintCardsCmd())
+	root.AddCommand(newEvalCmd())
 	root.AddCommand(newReduceCmd())
 	root.AddCommand(newEnrichCmd())
 	root.AddCommand(newClusterExportCmd())
 	root.AddCommand(newClusterLabelsCmd())
 	root.AddCommand(newWriteCardCmd())
 	root.AddCommand(newWriteInventoryCmd())
+	root.AddCommand(newWriteReleaseNotesCmd())
+	root.AddCommand(newInventoryCmd())
+	root.AddCommand(newConfigCmd())
+	root.AddCommand(newPromptsCmd())
+	root.AddCommand(newTaxonomyCmd())
+	root.AddCommand(newToolCmd())
 
 	if err := root.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+func newRunner(cfg config.Config) (llm.Runner, error) {
+	switch thinkingFlag {
+	case "", "low", "medium", "high":
+	default:
+		return llm.Runner{}, fmt.Errorf("invalid --thinking %q (want low|medium|high)", thinkingFlag)
+	}
+	runner, err := llm.NewRunner(cfg, modelFlag, promptDirFlag, llm.BackendConfig{Name: backendFlag, ReplayDir: replayDirFlag, BaseURL: baseURLFlag})
+	if err != nil {
+		return llm.Runner{}, err
+	}
+	runner.Thinking = thinkingFlag
+	return runner, nil
+}
+
 func newDiscoverCmd() *cobra.Command {
 	var limit int
 	var state string
+	var sample int
 	cmd := &cobra.Command{
 		Use:          "discover",
 		Short:        "Build a classification rubric from a corpus sample",
@@ -54,11 +103,21 @@ func newDiscoverCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return ingest.Discover(cmd.Context(), cfg, limit, state)
+			if err := ingest.Discover(cmd.Context(), cfg, limit, state, sample); err != nil {
+				return err
+			}
+			ensureSelfInPath()
+
+			runner, err := newRunner(cfg)
+			if err != nil {
+				return err
+			}
+			return runner.Discover(cmd.Context())
 		},
 	}
 	cmd.Flags().IntVar(&limit, "limit", 0, "Max PRs to sample from (0 = all)")
 	cmd.Flags().StringVar(&state, "state", "open", "PR state filter: open|closed|all")
+	cmd.Flags().IntVar(&sample, "sample", 30, "PRs to include in the sample (spread across the list; 0 = all)")
 	return cmd
 }
 
@@ -74,7 +133,11 @@ func newRunCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return ingest.Run(cmd.Context(), cfg, limit, state)
+			if err := ingest.Run(cmd.Context(), cfg, limit, state); err != nil {
+				return err
+			}
+			recordMetrics(cfg, metrics.StageIngest, nil)
+			return nil
 		},
 	}
 	cmd.Flags().IntVar(&limit, "limit", 0, "Max PRs to ingest (0 = all)")
@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/joshp123/github-triage/internal/config"
-	"github.com/joshp123/github-triage/internal/llm"
+	"github.com/joshp123/github-triage/internal/metrics"
 	"github.com/spf13/cobra"
 )
 
@@ -32,11 +32,14 @@ func newMapCmd() *cobra.Command {
 			}
 			ensureSelfInPath()
 
-			runner, err := llm.NewRunner(cfg, modelFlag)
+			runner, err := newRunner(cfg)
 			if err != nil {
 				return err
 			}
-			return runner.Map(cmd.Context(), cfg, limit, prNumbers, concurrencyFlag, state, order, timeout, skipExisting)
+			err = runner.Map(cmd.Context(), cfg, limit, prNumbers, concurrencyFlag, state, order, timeout, skipExisting)
+			// Failed runs cost tokens too.
+			recordMetrics(cfg, metrics.StageMap, &runner)
+			return err
 		},
 	}
 
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/prompts"
+	"github.com/spf13/cobra"
+)
+
+func newPromptsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "Inspect or export the LLM prompts",
+	}
+	cmd.AddCommand(newPromptsDumpCmd())
+	return cmd
+}
+
+func newPromptsDumpCmd() *cobra.Command {
+	var out string
+	var force bool
+	cmd := &cobra.Command{
+		Use:          "dump",
+		Short:        "Write the embedded prompts out for editing (default: <data-root>/triage/prompts, which overrides them)",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if out == "" {
+				cfg, err := config.Load(repoFlag)
+				if err != nil {
+					return err
+				}
+				out = cfg.PromptsDir
+			}
+			names, err := prompts.Names()
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				path := filepath.Join(out, filepath.FromSlash(name))
+				if _, err := os.Stat(path); err == nil && !force {
+					fmt.Fprintf(os.Stdout, "kept %s (exists; --force to overwrite)\n", path)
+					continue
+				}
+				data, err := prompts.FS.ReadFile(name)
+				if err != nil {
+					return err
+				}
+				if err := storage.WriteFileAtomic(path, data, 0o644); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stdout, "wrote %s\n", path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "Output dir (default: <data-root>/triage/prompts)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+	return cmd
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"time"
+
 	"github.com/joshp123/github-triage/internal/config"
-	"github.com/joshp123/github-triage/internal/llm"
+	"github.com/joshp123/github-triage/internal/inventory"
+	"github.com/joshp123/github-triage/internal/metrics"
 	"github.com/spf13/cobra"
 )
 
 func newReduceCmd() *cobra.Command {
-	return &cobra.Command{
+	var overview bool
+	var batchSize int
+	var timeout time.Duration
+	var formats []string
+	cmd := &cobra.Command{
 		Use:          "reduce",
-		Short:        "Run inventory snapshot over classification cards",
+		Short:        "Write the inventory snapshot from classification cards",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := config.Load(repoFlag)
@@ -19,13 +26,45 @@ func newReduceCmd() *cobra.Command {
 			if err := cfg.EnsureDirs(); err != nil {
 				return err
 			}
-			ensureSelfInPath()
 
-			runner, err := llm.NewRunner(cfg, modelFlag)
+			// Counts and grouped items are copied from the cards; no LLM.
+			paths, count, err := inventory.Write(cfg.DataRoot, "", formats)
+			if err != nil {
+				return err
+			}
+			if !overview {
+				printInventoryPaths(paths, count)
+				recordMetrics(cfg, metrics.StageReduce, nil)
+				return nil
+			}
+
+			ensureSelfInPath()
+			runner, err := newRunner(cfg)
+			if err != nil {
+				return err
+			}
+			err = runner.Reduce(cmd.Context(), batchSize, timeout)
+			recordMetrics(cfg, metrics.StageReduce, &runner)
+			if err != nil {
+				return err
+			}
+			// The tool writes the default formats; redo the requested ones
+			// with the overview.
+			snap, err := inventory.LoadSnapshot(cfg.DataRoot, "current")
+			if err != nil {
+				return err
+			}
+			paths, count, err = inventory.Write(cfg.DataRoot, snap.Overview, formats)
 			if err != nil {
 				return err
 			}
-			return runner.Reduce(cmd.Context())
+			printInventoryPaths(paths, count)
+			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&overview, "overview", false, "Also run the LLM to write a narrative overview section")
+	cmd.Flags().IntVar(&batchSize, "batch-size", 100, "Cards per overview batch (each batch is summarised, then the summaries merged)")
+	cmd.Flags().StringSliceVar(&formats, "format", inventory.DefaultFormats, "Output formats: md,json,csv,html (json is always written)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Per-prompt timeout for each batch and the merge (e.g. 10m)")
+	return cmd
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/gh"
+	"github.com/joshp123/github-triage/internal/release"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newReleaseNotesCmd() *cobra.Command {
+	var since string
+	var labels []string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:          "release-notes",
+		Short:        "Draft release notes from merged PRs carded good or needs-human (LLM)",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			if err := cfg.EnsureDirs(); err != nil {
+				return err
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			from, err := resolveSince(cmd, cfg, since)
+			if err != nil {
+				return err
+			}
+			in, err := release.Prepare(cfg, tax, since, from, labels)
+			if err != nil {
+				return err
+			}
+			if in.Skipped > 0 {
+				fmt.Fprintf(os.Stderr, "skipped %d merged PRs without mergedAt (re-run `triage run --state closed`)\n", in.Skipped)
+			}
+			if len(in.PRs) == 0 {
+				return fmt.Errorf("no merged PRs carded %s since %s (merged PRs come from `triage run --state closed` or --state all)", strings.Join(labels, "|"), from.Format(time.RFC3339))
+			}
+			fmt.Fprintf(os.Stderr, "%d merged PRs since %s\n", len(in.PRs), from.Format(time.RFC3339))
+
+			ensureSelfInPath()
+			runner, err := newRunner(cfg)
+			if err != nil {
+				return err
+			}
+			if err := runner.ReleaseNotes(cmd.Context(), timeout); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "wrote %s\n", release.DraftPath(cfg.DataRoot))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Last release: a git tag (resolved through the GitHub API) or a UTC date/time (2026-01-31, RFC3339)")
+	cmd.Flags().StringSliceVar(&labels, "label", release.DefaultLabels, "Card labels whose merged PRs are candidates")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "Prompt timeout (e.g. 10m)")
+	_ = cmd.MarkFlagRequired("since")
+	return cmd
+}
+
+// resolveSince turns a date, an RFC3339 time or a tag into the time the
+// release window starts; a tag is its commit's committer date.
+func resolveSince(cmd *cobra.Command, cfg config.Config, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	out, err := gh.Run(cmd.Context(), "api", fmt.Sprintf("/repos/%s/commits/%s", cfg.Repo, value), "--jq", ".commit.committer.date")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: not a date, and not a tag on %s: %w", value, cfg.Repo, err)
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("tag %s: parse commit date: %w", value, err)
+	}
+	return t.UTC(), nil
+}
+
+func newWriteReleaseNotesCmd() *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:          "write-release-notes",
+		Short:        "Write the release notes draft from a JSON sections file",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return writeReleaseNotes(file)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "-", `Notes JSON ({"intro": "...", "sections": [{"title": "Fixes", "entries": [{"prs": [12], "text": "..."}]}]}); - reads stdin`)
+
+	return cmd
+}
+
+func writeReleaseNotes(file string) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working dir: %w", err)
+	}
+	var data []byte
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("read notes: %w", err)
+	}
+	var notes release.Notes
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return fmt.Errorf("parse notes: %w", err)
+	}
+	path, err := release.Write(root, notes)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "wrote %s\n", path)
+	return nil
+}
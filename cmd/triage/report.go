@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/report"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd() *cobra.Command {
+	var since string
+	var format string
+	var out string
+	cmd := &cobra.Command{
+		Use:          "report",
+		Short:        "Daily report: ingest changes, new cards, label changes, close queue and what needs a human",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			now := time.Now().UTC()
+			start, err := parseSince(since, now)
+			if err != nil {
+				return err
+			}
+			rep, err := report.Build(cfg, tax, start, now)
+			if err != nil {
+				return err
+			}
+
+			var body string
+			switch format {
+			case "md":
+				body = report.Markdown(rep)
+			case "html":
+				body, err = report.HTML(rep)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid --format %q (want md|html)", format)
+			}
+			if out == "" {
+				fmt.Fprint(os.Stdout, body)
+				return nil
+			}
+			if err := storage.WriteFileAtomic(out, []byte(body), 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "wrote %s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "24h", "Report window: a duration (24h, 7d) or a UTC date/time (2026-01-31, RFC3339)")
+	cmd.Flags().StringVar(&format, "format", "md", "Output format: md|html")
+	cmd.Flags().StringVar(&out, "out", "", "Write the report to a file instead of stdout")
+	return cmd
+}
+
+// parseSince accepts Go durations plus whole days ("7d"), or an absolute
+// date or RFC3339 time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.Add(-time.Duration(n) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (want e.g. 24h, 7d, 2026-01-31)", value)
+}
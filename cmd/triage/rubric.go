@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joshp123/github-triage/docs"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/spf13/cobra"
+)
+
+func newRubricCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rubric",
+		Short: "Manage the classification rubric (triage/rubric.md)",
+	}
+	cmd.AddCommand(newRubricInitCmd())
+	return cmd
+}
+
+func newRubricInitCmd() *cobra.Command {
+	var from string
+	var force bool
+	cmd := &cobra.Command{
+		Use:          "init",
+		Short:        "Seed triage/rubric.md from docs/RUBRIC.md when it is missing",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			if err := cfg.EnsureDirs(); err != nil {
+				return err
+			}
+			if _, err := os.Stat(cfg.RubricPath); err == nil && !force {
+				fmt.Fprintf(os.Stdout, "rubric exists: %s (v%d)\n", cfg.RubricPath, rubric.CurrentVersion(cfg.DataRoot))
+				return nil
+			}
+			data := docs.Rubric
+			if from != "" {
+				data, err = os.ReadFile(from)
+				if err != nil {
+					return fmt.Errorf("read seed rubric: %w", err)
+				}
+			}
+			version, warnings, err := rubric.Write(cfg.DataRoot, string(data), "seed")
+			if err != nil {
+				return err
+			}
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+			}
+			fmt.Fprintf(os.Stdout, "wrote %s (v%d)\n", cfg.RubricPath, version)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Seed rubric path (default: the docs/RUBRIC.md built into the binary)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing rubric (stored as a new version)")
+	return cmd
+}
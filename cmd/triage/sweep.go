@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/joshp123/github-triage/internal/config"
-	"github.com/joshp123/github-triage/internal/llm"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +28,7 @@ func newSweepCmd() *cobra.Command {
 			}
 			ensureSelfInPath()
 
-			runner, err := llm.NewRunner(cfg, modelFlag)
+			runner, err := newRunner(cfg)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newTaxonomyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taxonomy",
+		Short: "Inspect or export the label taxonomy",
+	}
+	cmd.AddCommand(newTaxonomyInitCmd())
+	cmd.AddCommand(newTaxonomyShowCmd())
+	return cmd
+}
+
+func newTaxonomyInitCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:          "init",
+		Short:        "Write the default taxonomy to <data-root>/triage/taxonomy.toml for editing",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			path := taxonomy.Path(cfg.DataRoot)
+			if _, err := os.Stat(path); err == nil && !force {
+				return fmt.Errorf("%s exists (use --force to overwrite)", path)
+			}
+			data, err := taxonomy.Default().Encode()
+			if err != nil {
+				return err
+			}
+			if err := storage.WriteFileAtomic(path, data, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "wrote %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing taxonomy")
+	return cmd
+}
+
+func newTaxonomyShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "show",
+		Short:        "Print the labels in effect for the repo",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			tax, source, err := taxonomy.LoadWithSource(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "source: %s\n", source)
+			for _, label := range tax.Labels {
+				line := fmt.Sprintf("%s (%s)", label.Name, tax.Display(label.Name))
+				if len(label.Stages) > 0 {
+					line += " stages=" + strings.Join(label.Stages, ",")
+				}
+				if label.Closable {
+					line += " closable"
+				}
+				if label.Fallback {
+					line += " fallback"
+				}
+				if label.NeedsHuman {
+					line += " needs_human"
+				}
+				if label.Description != "" {
+					line += " — " + label.Description
+				}
+				fmt.Fprintln(os.Stdout, line)
+			}
+			return nil
+		},
+	}
+}
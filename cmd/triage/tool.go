@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/policy"
+	"github.com/joshp123/github-triage/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+func newToolCmd() *cobra.Command {
+	var cardDir string
+	var stage string
+	var blockedLog string
+	var prov card.Provenance
+	var rawArgs string
+	cmd := &cobra.Command{
+		Use:          "tool <name>",
+		Short:        "Call an LLM tool with JSON arguments (LLM-facing bridge)",
+		Long:         "Call an LLM tool with a JSON arguments object from --args or stdin. Tools: " + strings.Join(tools.Names, ", "),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			// A session the runner started fixes the stage, blocked log and
+			// PR; the command line cannot widen them.
+			pr := 0
+			if session := os.Getenv(policy.StageEnv); session != "" {
+				if stage != "" && stage != session {
+					return fmt.Errorf("--stage %s does not match this session's stage %s", stage, session)
+				}
+				stage = session
+				blockedLog = os.Getenv(policy.BlockedLogEnv)
+				if value := os.Getenv(policy.PREnv); value != "" {
+					if pr, err = strconv.Atoi(value); err != nil {
+						return fmt.Errorf("invalid $%s %q", policy.PREnv, value)
+					}
+				}
+			} else if stage == "" {
+				return errors.New("--stage is required")
+			}
+			if blockedLog != "" {
+				if !filepath.IsAbs(blockedLog) {
+					blockedLog = filepath.Join(cfg.DataRoot, blockedLog)
+				}
+				if rel, err := filepath.Rel(cfg.TriageDir, blockedLog); err != nil || strings.HasPrefix(rel, "..") || filepath.Ext(blockedLog) != ".jsonl" {
+					return fmt.Errorf("--blocked-log %s must be a .jsonl file under triage/", blockedLog)
+				}
+			}
+			toolCtx := tools.Context{Config: cfg, CardDir: cardDir, Policy: policy.ForStage(stage), BlockedLog: blockedLog, Provenance: prov, PR: pr}
+			tool, err := tools.Lookup(toolCtx, args[0])
+			if err != nil {
+				return err
+			}
+			input := []byte(rawArgs)
+			if strings.TrimSpace(rawArgs) == "" {
+				input, err = io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("read stdin: %w", err)
+				}
+			}
+			if !json.Valid(input) {
+				return fmt.Errorf("arguments for %s must be a JSON object", args[0])
+			}
+			out, err := tool.Handler(cmd.Context(), input)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stdout, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cardDir, "card-dir", "", "Card directory relative to the data root (write_card): triage/map, triage/sweep or triage/eval/<run>/cards (default: the stage's)")
+	cmd.Flags().StringVar(&stage, "stage", "", "Stage whose tool policy applies (default: $TRIAGE_STAGE, set for runner sessions)")
+	cmd.Flags().StringVar(&blockedLog, "blocked-log", "", "Append refused calls to this JSONL log (relative to the data root)")
+	cmd.Flags().StringVar(&prov.Prompt, "prompt-id", "", "Prompt stamp for cards (write_card)")
+	cmd.Flags().StringVar(&prov.Model, "model-id", "", "Model id for cards (write_card)")
+	cmd.Flags().StringVar(&prov.RunID, "run-id", "", "Run id for cards (write_card)")
+	cmd.Flags().StringVar(&rawArgs, "args", "", "JSON arguments (default: read from stdin)")
+	return cmd
+}
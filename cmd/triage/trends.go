@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/llm"
+	"github.com/joshp123/github-triage/internal/metrics"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/spf13/cobra"
+)
+
+func newTrendsCmd() *cobra.Command {
+	var since string
+	var by string
+	var format string
+	var out string
+	cmd := &cobra.Command{
+		Use:          "trends",
+		Short:        "Trends across runs from triage/metrics.jsonl: sparklines or CSV",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(repoFlag)
+			if err != nil {
+				return err
+			}
+			tax, err := taxonomy.Load(cfg.DataRoot)
+			if err != nil {
+				return err
+			}
+			start, err := parseSince(since, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			points, err := metrics.Read(cfg.MetricsPath, start)
+			if err != nil {
+				return err
+			}
+			periods, err := metrics.Group(points, by)
+			if err != nil {
+				return err
+			}
+
+			var body []byte
+			switch format {
+			case "text":
+				body = []byte(metrics.Render(tax, periods, by))
+			case "csv":
+				body, err = metrics.CSV(tax, periods)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("invalid --format %q (want text|csv)", format)
+			}
+			if out == "" {
+				_, err := os.Stdout.Write(body)
+				return err
+			}
+			if err := storage.WriteFileAtomic(out, body, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "wrote %s\n", out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "90d", "Window: a duration (90d, 2160h) or a UTC date/time (2026-01-31, RFC3339)")
+	cmd.Flags().StringVar(&by, "by", "day", "One value per: run|day|week (last counts of the period, usage summed)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text (sparklines)|csv")
+	cmd.Flags().StringVar(&out, "out", "", "Write to a file instead of stdout")
+	return cmd
+}
+
+// recordMetrics appends a point to triage/metrics.jsonl after a stage ran.
+// runner is nil for stages that did not call the model. A failure is only
+// logged: the stage's own result stands.
+func recordMetrics(cfg config.Config, stage string, runner *llm.Runner) {
+	point, err := collectMetrics(cfg, stage, runner)
+	if err == nil {
+		err = metrics.Append(cfg.MetricsPath, point)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "metrics: %s\n", err)
+	}
+}
+
+func collectMetrics(cfg config.Config, stage string, runner *llm.Runner) (metrics.Point, error) {
+	tax, err := taxonomy.Load(cfg.DataRoot)
+	if err != nil {
+		return metrics.Point{}, err
+	}
+	point, err := metrics.Collect(cfg, tax, stage, time.Now().UTC())
+	if err != nil {
+		return metrics.Point{}, err
+	}
+	if runner != nil && runner.Usage != nil {
+		point.Usage = &metrics.Usage{
+			Backend:      runner.Backend.Name(),
+			Model:        runner.Provider + "/" + runner.Model,
+			Sessions:     runner.Usage.Sessions.Load(),
+			InputTokens:  runner.Usage.InputTokens.Load(),
+			OutputTokens: runner.Usage.OutputTokens.Load(),
+		}
+	}
+	return point, nil
+}
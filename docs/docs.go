@@ -0,0 +1,11 @@
+// Package docs embeds the seed rubric so `triage rubric init` works outside
+// a checkout.
+package docs
+
+import _ "embed"
+
+// Rubric is docs/RUBRIC.md, the rubric `triage rubric init` seeds
+// triage/rubric.md from unless --from names another file.
+//
+//go:embed RUBRIC.md
+var Rubric []byte
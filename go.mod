@@ -3,13 +3,9 @@ module github.com/joshp123/github-triage
 go 1.22
 
 require (
-	github.com/joshp123/pi-golang v0.0.0
+	github.com/BurntSushi/toml v1.4.0
 	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
 )
 
-replace github.com/joshp123/pi-golang => ../pi-golang
-
-require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
-)
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,314 @@
+// Package aging reports how long open PRs have waited: open PRs bucketed by
+// age and label, time to first maintainer response, and PRs waiting on a
+// human (needs_human labels) that no maintainer has touched for a while. Everything is read from the data root
+// (raw snapshots, state.json, map cards, maintainers.txt and the enrich
+// comment cache); nothing calls GitHub or the LLM.
+package aging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Bucket is an age range; a PR falls in the first bucket whose MaxDays it
+// does not exceed (MaxDays 0 is unbounded).
+type Bucket struct {
+	Name    string `json:"name"`
+	MaxDays int    `json:"max_days,omitempty"`
+}
+
+// Buckets are the age ranges, youngest first.
+var Buckets = []Bucket{
+	{Name: "≤7d", MaxDays: 7},
+	{Name: "8–30d", MaxDays: 30},
+	{Name: "31–90d", MaxDays: 90},
+	{Name: "91–180d", MaxDays: 180},
+	{Name: ">180d"},
+}
+
+// UnknownBucket holds PRs whose snapshot predates createdAt (re-run
+// `triage run`).
+const UnknownBucket = "unknown"
+
+// Uncarded is the label key of open PRs without a map card.
+const Uncarded = ""
+
+// PR is one open PR.
+type PR struct {
+	Number    int        `json:"pr"`
+	Title     string     `json:"title,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Label     string     `json:"label"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	AgeDays   int        `json:"age_days"`
+	Bucket    string     `json:"bucket"`
+	// FirstResponse is the first maintainer comment or review; LastTouch the
+	// latest. Both are nil when there is none in the cache.
+	FirstResponse *time.Time `json:"first_response,omitempty"`
+	LastTouch     *time.Time `json:"last_touch,omitempty"`
+	// IdleDays counts from LastTouch, or from CreatedAt when untouched.
+	IdleDays int `json:"idle_days"`
+	// Cached is false when enrich has not fetched comments or reviews, so
+	// maintainer responses are unknown rather than absent.
+	Cached bool `json:"cached"`
+}
+
+// Row is one label's open PRs per bucket, in Buckets order then unknown.
+type Row struct {
+	Label   string `json:"label"`
+	Display string `json:"display"`
+	Counts  []int  `json:"counts"`
+	Total   int    `json:"total"`
+	// Responded counts PRs with a maintainer response; MedianResponseDays
+	// is the median time to it.
+	Responded          int     `json:"responded"`
+	MedianResponseDays float64 `json:"median_response_days"`
+}
+
+// Report is the aging report.
+type Report struct {
+	Repo        string    `json:"repo"`
+	GeneratedAt time.Time `json:"generated_at"`
+	StaleDays   int       `json:"stale_days"`
+	Buckets     []string  `json:"buckets"`
+	Rows        []Row     `json:"rows"`
+	Open        int       `json:"open"`
+	// Maintainer counts open maintainer-authored PRs, which are left out.
+	Maintainer int `json:"maintainer"`
+	// Stale are PRs whose label the taxonomy marks needs_human with no
+	// maintainer touch for StaleDays, longest idle first. Only PRs with a
+	// comment cache count; Unchecked holds the ones at least StaleDays old
+	// without one, whose maintainer touches are unknown.
+	Stale     []PR `json:"stale"`
+	Unchecked []PR `json:"unchecked"`
+	PRs       []PR `json:"prs"`
+}
+
+type rawPR struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"createdAt"`
+	Author    struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// activity is one comment, review or review comment in the enrich cache.
+type activity struct {
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	CreatedAt   string `json:"created_at"`
+	SubmittedAt string `json:"submitted_at"`
+}
+
+// Build collects the report for the raw-cached PRs state.json shows open.
+func Build(cfg config.Config, tax taxonomy.Taxonomy, staleDays int, now time.Time) (Report, error) {
+	if staleDays <= 0 {
+		return Report{}, fmt.Errorf("invalid stale days %d (want > 0)", staleDays)
+	}
+	rep := Report{Repo: cfg.Repo, GeneratedAt: now, StaleDays: staleDays, Stale: []PR{}, Unchecked: []PR{}, PRs: []PR{}}
+	for _, bucket := range Buckets {
+		rep.Buckets = append(rep.Buckets, bucket.Name)
+	}
+	rep.Buckets = append(rep.Buckets, UnknownBucket)
+
+	labels := map[int]card.Record{}
+	if _, err := os.Stat(cfg.MapDir); err == nil {
+		records, err := card.ReadDir(cfg.MapDir)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, rec := range records {
+			labels[rec.PR] = rec
+		}
+	}
+	maintainers := loadMaintainers(cfg.Maintainers)
+	open, err := ingest.OpenPRs(cfg)
+	if err != nil {
+		return Report{}, err
+	}
+	for _, cached := range open {
+		number := cached.Number
+		raw := rawPR{}
+		if err := json.Unmarshal(cached.Raw, &raw); err != nil {
+			return Report{}, fmt.Errorf("parse pr-%d: %w", number, err)
+		}
+		rec, carded := labels[number]
+		if maintainers[raw.Author.Login] || (carded && rec.Maintainer) {
+			rep.Maintainer++
+			continue
+		}
+		p := PR{Number: number, Title: raw.Title, URL: raw.URL, Author: raw.Author.Login, Label: Uncarded, Bucket: UnknownBucket}
+		if carded {
+			p.Label = rec.Label
+		}
+		if created, err := time.Parse(time.RFC3339, raw.CreatedAt); err == nil {
+			created = created.UTC()
+			p.CreatedAt = &created
+			p.AgeDays = days(now.Sub(created))
+			p.Bucket = bucketOf(p.AgeDays)
+		}
+		touches(cfg, number, raw.Author.Login, maintainers, &p)
+		since := p.LastTouch
+		if since == nil {
+			since = p.CreatedAt
+		}
+		if since != nil {
+			p.IdleDays = days(now.Sub(*since))
+		}
+		rep.PRs = append(rep.PRs, p)
+	}
+	sort.Slice(rep.PRs, func(i, j int) bool { return rep.PRs[i].Number < rep.PRs[j].Number })
+	rep.Open = len(rep.PRs)
+
+	// Rows in taxonomy order, then uncarded and any label the taxonomy
+	// no longer has.
+	order := tax.Names()
+	known := map[string]bool{}
+	for _, label := range order {
+		known[label] = true
+	}
+	extra := []string{}
+	for _, p := range rep.PRs {
+		if !known[p.Label] {
+			known[p.Label] = true
+			extra = append(extra, p.Label)
+		}
+	}
+	sort.Strings(extra)
+	for _, label := range append(order, extra...) {
+		row := Row{Label: label, Display: display(tax, label), Counts: make([]int, len(rep.Buckets))}
+		responses := []float64{}
+		for _, p := range rep.PRs {
+			if p.Label != label {
+				continue
+			}
+			row.Total++
+			for i, name := range rep.Buckets {
+				if name == p.Bucket {
+					row.Counts[i]++
+				}
+			}
+			if p.FirstResponse != nil && p.CreatedAt != nil {
+				row.Responded++
+				responses = append(responses, p.FirstResponse.Sub(*p.CreatedAt).Hours()/24)
+			}
+		}
+		if _, ok := tax.Find(label); row.Total == 0 && !ok {
+			continue
+		}
+		row.MedianResponseDays = median(responses)
+		rep.Rows = append(rep.Rows, row)
+	}
+
+	for _, p := range rep.PRs {
+		switch {
+		case !tax.NeedsHuman(p.Label) || p.IdleDays < staleDays:
+		case p.Cached:
+			rep.Stale = append(rep.Stale, p)
+		default:
+			rep.Unchecked = append(rep.Unchecked, p)
+		}
+	}
+	sort.SliceStable(rep.Stale, func(i, j int) bool { return rep.Stale[i].IdleDays > rep.Stale[j].IdleDays })
+	sort.SliceStable(rep.Unchecked, func(i, j int) bool { return rep.Unchecked[i].IdleDays > rep.Unchecked[j].IdleDays })
+	return rep, nil
+}
+
+// touches fills the first and last maintainer response from the comments,
+// reviews and review comments cached by `triage enrich`.
+func touches(cfg config.Config, pr int, author string, maintainers map[string]bool, p *PR) {
+	for _, path := range []string{cfg.RawPRCommentsPath(pr), cfg.RawPRReviewsPath(pr), cfg.RawPRReviewCommentsPath(pr)} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		p.Cached = true
+		var items []activity
+		if err := json.Unmarshal(data, &items); err != nil {
+			continue
+		}
+		for _, item := range items {
+			login := item.User.Login
+			if login == author || !maintainers[login] {
+				continue
+			}
+			stamp := item.CreatedAt
+			if stamp == "" {
+				stamp = item.SubmittedAt
+			}
+			at, err := time.Parse(time.RFC3339, stamp)
+			if err != nil {
+				continue
+			}
+			at = at.UTC()
+			if p.FirstResponse == nil || at.Before(*p.FirstResponse) {
+				p.FirstResponse = &at
+			}
+			if p.LastTouch == nil || at.After(*p.LastTouch) {
+				p.LastTouch = &at
+			}
+		}
+	}
+}
+
+func loadMaintainers(path string) map[string]bool {
+	out := map[string]bool{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return out
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if login := strings.TrimSpace(line); login != "" {
+			out[login] = true
+		}
+	}
+	return out
+}
+
+func bucketOf(age int) string {
+	for _, bucket := range Buckets {
+		if bucket.MaxDays == 0 || age <= bucket.MaxDays {
+			return bucket.Name
+		}
+	}
+	return UnknownBucket
+}
+
+func days(d time.Duration) int {
+	if d < 0 {
+		return 0
+	}
+	return int(d / (24 * time.Hour))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
+
+func display(tax taxonomy.Taxonomy, label string) string {
+	if label == Uncarded {
+		return "not carded"
+	}
+	return tax.Display(label)
+}
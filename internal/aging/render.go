@@ -0,0 +1,113 @@
+package aging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxStale caps the stale and unchecked lists in Markdown; JSON has every
+// PR.
+const MaxStale = 50
+
+// Markdown renders the report: the age table, first-response times, and the
+// stale PRs that need a human, with those lacking a comment cache apart.
+func Markdown(rep Report) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# PR aging — %s\n", rep.Repo))
+	b.WriteString(fmt.Sprintf("%s UTC · %d open", rep.GeneratedAt.UTC().Format("2006-01-02 15:04"), rep.Open))
+	if rep.Maintainer > 0 {
+		b.WriteString(fmt.Sprintf(" (+%d maintainer-authored, not shown)", rep.Maintainer))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString("## Open PRs by age\n")
+	b.WriteString("| label | " + strings.Join(rep.Buckets, " | ") + " | total |\n")
+	b.WriteString("|---" + strings.Repeat("|---:", len(rep.Buckets)+1) + "|\n")
+	totals := make([]int, len(rep.Buckets))
+	for _, row := range rep.Rows {
+		cells := []string{row.Display}
+		for i, n := range row.Counts {
+			cells = append(cells, fmt.Sprint(n))
+			totals[i] += n
+		}
+		cells = append(cells, fmt.Sprint(row.Total))
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	cells := []string{"**all**"}
+	for _, n := range totals {
+		cells = append(cells, fmt.Sprint(n))
+	}
+	cells = append(cells, fmt.Sprint(rep.Open))
+	b.WriteString("| " + strings.Join(cells, " | ") + " |\n\n")
+
+	b.WriteString("## First maintainer response\n")
+	uncached := 0
+	for _, p := range rep.PRs {
+		if !p.Cached {
+			uncached++
+		}
+	}
+	for _, row := range rep.Rows {
+		if row.Total == 0 {
+			continue
+		}
+		line := fmt.Sprintf("- %s: %d of %d answered", row.Display, row.Responded, row.Total)
+		if row.Responded > 0 {
+			line += fmt.Sprintf(", median %.1fd", row.MedianResponseDays)
+		}
+		b.WriteString(line + "\n")
+	}
+	if uncached > 0 {
+		b.WriteString(fmt.Sprintf("- %d open PRs have no comments or reviews cached (run `triage enrich`)\n", uncached))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(fmt.Sprintf("## Needs a human, no maintainer touch for %dd+ (%d)\n", rep.StaleDays, len(rep.Stale)))
+	if len(rep.Stale) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	writePRs(&b, rep.Stale)
+
+	if len(rep.Unchecked) > 0 {
+		b.WriteString(fmt.Sprintf("\n## Needs a human, %dd+ old, no comments cached (%d)\n", rep.StaleDays, len(rep.Unchecked)))
+		b.WriteString("Maintainer touches are unknown until `triage enrich` caches comments and reviews.\n")
+		writePRs(&b, rep.Unchecked)
+	}
+	return b.String()
+}
+
+func writePRs(b *strings.Builder, prs []PR) {
+	for i, p := range prs {
+		if i == MaxStale {
+			b.WriteString(fmt.Sprintf("- … and %d more\n", len(prs)-MaxStale))
+			break
+		}
+		line := fmt.Sprintf("- #%d", p.Number)
+		if p.Title != "" {
+			line += " " + p.Title
+		}
+		if p.Author != "" {
+			line += " (@" + p.Author + ")"
+		}
+		if p.Cached {
+			touch := "never touched"
+			if p.LastTouch != nil {
+				touch = "last touch " + p.LastTouch.Format("2006-01-02")
+			}
+			line += fmt.Sprintf(" — %dd idle, %dd old, %s", p.IdleDays, p.AgeDays, touch)
+		} else {
+			line += fmt.Sprintf(" — %dd old", p.AgeDays)
+		}
+		b.WriteString(line + "\n")
+	}
+}
+
+// JSON renders the report with every open PR.
+func JSON(rep Report) ([]byte, error) {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal aging report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,244 @@
+package card
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/evidence"
+	"github.com/joshp123/github-triage/internal/injection"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Input is what the model supplies for one card, either as write-card flags
+// or as write_card tool arguments.
+type Input struct {
+	PR         int      `json:"pr"`
+	Author     string   `json:"author"`
+	Maintainer string   `json:"maintainer,omitempty"`
+	Label      string   `json:"label,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Evidence   []string `json:"evidence,omitempty"`
+	Notes      []string `json:"notes,omitempty"`
+}
+
+// Provenance records what produced a card. The rubric stamp is filled in by
+// Write from triage/rubric.md; the rest comes from the runner.
+type Provenance struct {
+	Rubric string `json:"rubric,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+	Model  string `json:"model,omitempty"`
+	RunID  string `json:"run_id,omitempty"`
+}
+
+// Write validates in and writes pr-N.card.json plus its Markdown view pr-N.md
+// into cardDir. root is the data root; a relative cardDir is resolved against
+// it. It returns the Markdown path.
+func Write(root string, cardDir string, in Input, prov Provenance) (string, error) {
+	if in.PR <= 0 {
+		return "", errors.New("--pr must be > 0")
+	}
+	author := strings.TrimSpace(in.Author)
+	if author == "" {
+		return "", errors.New("--author is required")
+	}
+
+	label := strings.TrimSpace(in.Label)
+	summary := strings.TrimSpace(in.Summary)
+	items := TrimStrings(in.Evidence)
+	notes := TrimStrings(in.Notes)
+
+	maintainer, err := ResolveMaintainer(root, in.Maintainer, author)
+	if err != nil {
+		return "", err
+	}
+
+	if maintainer {
+		label = ""
+		summary = ""
+		items = nil
+		notes = nil
+	} else {
+		tax, err := taxonomy.Load(root)
+		if err != nil {
+			return "", err
+		}
+		if err := tax.Validate(label, ""); err != nil {
+			return "", err
+		}
+		if summary == "" {
+			return "", errors.New("--summary is required when maintainer=no")
+		}
+		if len(items) == 0 {
+			return "", errors.New("--evidence is required when maintainer=no")
+		}
+	}
+
+	rec := Record{
+		SchemaVersion: SchemaVersion,
+		PR:            in.PR,
+		Author:        author,
+		Maintainer:    maintainer,
+		Label:         label,
+		Injection:     !maintainer && injection.IsSuspected(root, in.PR),
+		Summary:       summary,
+		Evidence:      evidence.Verify(root, in.PR, items),
+		Notes:         notes,
+		Provenance:    prov,
+		WrittenAt:     time.Now().UTC(),
+	}
+	rec.Provenance.Rubric = rubric.Stamp(root)
+	if !maintainer && !rec.HasVerifiedEvidence() {
+		reasons := []string{}
+		for i, ev := range rec.Evidence {
+			reasons = append(reasons, fmt.Sprintf("%d: %s", i+1, ev.Reason))
+		}
+		return "", fmt.Errorf("no evidence could be verified against the cached files (%s); quote text exactly as it appears and name the file as the source", strings.Join(reasons, "; "))
+	}
+
+	if strings.TrimSpace(cardDir) == "" {
+		cardDir = filepath.Join("triage", "map")
+	}
+	if !filepath.IsAbs(cardDir) {
+		cardDir = filepath.Join(root, cardDir)
+	}
+	return save(cardDir, rec)
+}
+
+// save writes the JSON sidecar, then the Markdown view rendered from it. It
+// returns the Markdown path.
+func save(cardDir string, rec Record) (string, error) {
+	if err := storage.WriteJSONAtomic(JSONPath(cardDir, rec.PR), rec); err != nil {
+		return "", err
+	}
+	path := MarkdownPath(cardDir, rec.PR)
+	if err := storage.WriteFileAtomic(path, []byte(Render(rec)), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Render formats the Markdown view of a card. An injection-flagged PR gets an
+// "Injection: suspected" header line; empty provenance fields are left out.
+// Maintainer cards show placeholders instead of a classification.
+func Render(rec Record) string {
+	label, summary := rec.Label, rec.Summary
+	quotes := make([]string, 0, len(rec.Evidence))
+	for _, ev := range rec.Evidence {
+		switch {
+		case rec.SchemaVersion < 2:
+			quotes = append(quotes, ev.Text)
+		case ev.Verified:
+			quotes = append(quotes, ev.Text+" — verified")
+		default:
+			quotes = append(quotes, fmt.Sprintf("%s — unverified (%s)", ev.Text, ev.Reason))
+		}
+	}
+	if rec.Maintainer {
+		label = "(none)"
+		summary = "skipped (maintainer)"
+		quotes = []string{"skipped (maintainer)"}
+	}
+
+	var b strings.Builder
+	b.WriteString("# PR Classification\n")
+	b.WriteString(fmt.Sprintf("PR: #%d\n", rec.PR))
+	b.WriteString(fmt.Sprintf("Author: %s\n", rec.Author))
+	if rec.Maintainer {
+		b.WriteString("Maintainer: yes\n")
+	} else {
+		b.WriteString("Maintainer: no\n")
+	}
+	b.WriteString(fmt.Sprintf("Label: %s\n", label))
+	if rec.Injection {
+		b.WriteString(fmt.Sprintf("Injection: %s\n", injection.Suspected))
+	}
+	prov := rec.Provenance
+	for _, field := range [][2]string{{"Rubric", prov.Rubric}, {"Prompt", prov.Prompt}, {"Model", prov.Model}, {"Run", prov.RunID}} {
+		if value := strings.TrimSpace(field[1]); value != "" {
+			b.WriteString(fmt.Sprintf("%s: %s\n", field[0], value))
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Summary\n")
+	b.WriteString(fmt.Sprintf("- %s\n\n", summary))
+
+	b.WriteString("## Evidence\n")
+	for _, item := range quotes {
+		b.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+	b.WriteString("\n")
+
+	if len(rec.Notes) > 0 {
+		b.WriteString("## Notes\n")
+		for _, note := range rec.Notes {
+			b.WriteString(fmt.Sprintf("- %s\n", note))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// AppendNotes adds notes to the card whose sidecar is at path and rewrites
+// both files.
+func AppendNotes(path string, notes []string) error {
+	notes = TrimStrings(notes)
+	if len(notes) == 0 {
+		return nil
+	}
+	rec, err := Read(path)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		rec.Notes = append(rec.Notes, strings.ReplaceAll(note, "\n", " "))
+	}
+	_, err = save(filepath.Dir(path), rec)
+	return err
+}
+
+func ResolveMaintainer(root string, mode string, author string) (bool, error) {
+	mode = strings.TrimSpace(mode)
+	switch mode {
+	case "", "auto":
+		return lookupMaintainer(root, author)
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid --maintainer %q (want auto|yes|no)", mode)
+	}
+}
+
+func lookupMaintainer(root string, author string) (bool, error) {
+	path := filepath.Join(root, "triage", "maintainers.txt")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, nil
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == author {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TrimStrings(items []string) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		value := strings.TrimSpace(item)
+		if value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
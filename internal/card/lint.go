@@ -0,0 +1,175 @@
+package card
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Evidence items are `"quote" (source)`: a non-empty quote and a source.
+var (
+	evidenceQuoteRe  = regexp.MustCompile(`"[^"]*[^"\s][^"]*"`)
+	evidenceSourceRe = regexp.MustCompile(`\([^()]*[^()\s][^()]*\)\s*$`)
+	closeReadyRe     = regexp.MustCompile(`(?i)^close-ready:\s*(yes|no)\b`)
+)
+
+// Lint checks a card against the schema and the taxonomy. stage is the card
+// stage (map|sweep) whose label set and note rules apply; empty checks only
+// what every stage shares.
+func Lint(rec Record, tax taxonomy.Taxonomy, stage string) []string {
+	issues := []string{}
+	// Older schemas (e.g. cards from `triage cards --migrate`) are what Read
+	// decodes; only versions it cannot decode are an issue.
+	if rec.SchemaVersion < 1 || rec.SchemaVersion > SchemaVersion {
+		issues = append(issues, fmt.Sprintf("schema_version %d (want 1..%d)", rec.SchemaVersion, SchemaVersion))
+	}
+	if strings.TrimSpace(rec.Author) == "" {
+		issues = append(issues, "author is empty")
+	}
+	if rec.WrittenAt.IsZero() {
+		issues = append(issues, "written_at is missing")
+	}
+	if rec.Maintainer {
+		if rec.Label != "" || rec.Summary != "" || len(rec.Evidence) > 0 {
+			issues = append(issues, "maintainer card carries a classification")
+		}
+		return issues
+	}
+
+	if err := tax.Validate(rec.Label, stage); err != nil {
+		issues = append(issues, strings.Replace(err.Error(), "invalid --label", "label", 1))
+	}
+	if strings.TrimSpace(rec.Summary) == "" {
+		issues = append(issues, "summary is empty")
+	}
+	if len(rec.Evidence) == 0 {
+		issues = append(issues, "evidence is empty")
+	}
+	for i, ev := range rec.Evidence {
+		if !evidenceQuoteRe.MatchString(ev.Text) {
+			issues = append(issues, fmt.Sprintf("evidence %d quotes nothing: %s", i+1, ev.Text))
+		} else if !evidenceSourceRe.MatchString(ev.Text) {
+			issues = append(issues, fmt.Sprintf("evidence %d has no (source): %s", i+1, ev.Text))
+		}
+	}
+	if len(rec.Evidence) > 0 && rec.SchemaVersion >= 2 && !rec.HasVerifiedEvidence() {
+		issues = append(issues, "no evidence verified against the cached files")
+	}
+	if stage == "sweep" {
+		marks := 0
+		for _, note := range rec.Notes {
+			if closeReadyRe.MatchString(strings.TrimSpace(note)) {
+				marks++
+			}
+		}
+		switch {
+		case marks == 0:
+			issues = append(issues, "notes lack a close-ready: yes|no marker")
+		case marks > 1:
+			issues = append(issues, "notes have more than one close-ready marker")
+		}
+	}
+	return issues
+}
+
+// Problem is a card that failed lint. Files are the card's files in its
+// directory (sidecar, Markdown view, blocked log).
+type Problem struct {
+	PR     int
+	Issues []string
+	Files  []string
+}
+
+// LintDir lints every card in cardDir, including Markdown cards without a
+// sidecar and sidecars without a Markdown view. Problems are ordered by PR.
+func LintDir(cardDir string, tax taxonomy.Taxonomy, stage string) (int, []Problem, error) {
+	entries, err := os.ReadDir(cardDir)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read cards dir: %w", err)
+	}
+	files := map[int][]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "pr-") {
+			continue
+		}
+		digits := strings.TrimPrefix(name, "pr-")
+		if i := strings.IndexByte(digits, '.'); i > 0 {
+			digits = digits[:i]
+		}
+		pr, err := strconv.Atoi(digits)
+		if err != nil {
+			continue
+		}
+		files[pr] = append(files[pr], filepath.Join(cardDir, name))
+	}
+
+	prs := make([]int, 0, len(files))
+	for pr := range files {
+		prs = append(prs, pr)
+	}
+	sort.Ints(prs)
+
+	total := 0
+	problems := []Problem{}
+	for _, pr := range prs {
+		jsonPath, mdPath := JSONPath(cardDir, pr), MarkdownPath(cardDir, pr)
+		hasJSON, hasMD := contains(files[pr], jsonPath), contains(files[pr], mdPath)
+		if !hasJSON && !hasMD {
+			continue
+		}
+		total++
+		issues := []string{}
+		switch {
+		case !hasJSON:
+			issues = append(issues, "no pr-N.card.json (Markdown only)")
+		default:
+			rec, err := Read(jsonPath)
+			if err != nil {
+				issues = append(issues, err.Error())
+				break
+			}
+			if rec.PR != pr {
+				issues = append(issues, fmt.Sprintf("card is for PR %d", rec.PR))
+			}
+			if !hasMD {
+				issues = append(issues, "no pr-N.md view")
+			}
+			issues = append(issues, Lint(rec, tax, stage)...)
+		}
+		if len(issues) > 0 {
+			problems = append(problems, Problem{PR: pr, Issues: issues, Files: files[pr]})
+		}
+	}
+	return total, problems, nil
+}
+
+// Quarantine moves a problem card's files into dir with a pr-N.lint.txt
+// listing its issues, so the next run regenerates the card.
+func Quarantine(dir string, p Problem) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+	for _, path := range p.Files {
+		if err := os.Rename(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+			return fmt.Errorf("quarantine %s: %w", path, err)
+		}
+	}
+	report := "- " + strings.Join(p.Issues, "\n- ") + "\n"
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("pr-%d.lint.txt", p.PR)), []byte(report), 0o644)
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
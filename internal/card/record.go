@@ -0,0 +1,257 @@
+package card
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/evidence"
+	"github.com/joshp123/github-triage/internal/injection"
+)
+
+// SchemaVersion is the pr-N.card.json schema written by this build. Bump it
+// when a field changes meaning; Read rejects newer versions.
+//
+//	1: evidence is a list of strings
+//	2: evidence items are objects with their verification result
+const SchemaVersion = 2
+
+// Record is the machine-readable card (pr-N.card.json). Every consumer reads
+// this; pr-N.md is rendered from it for humans. Maintainer cards carry no
+// label, summary or evidence.
+type Record struct {
+	SchemaVersion int             `json:"schema_version"`
+	PR            int             `json:"pr"`
+	Author        string          `json:"author"`
+	Maintainer    bool            `json:"maintainer"`
+	Label         string          `json:"label,omitempty"`
+	Injection     bool            `json:"injection,omitempty"`
+	Summary       string          `json:"summary,omitempty"`
+	Evidence      []evidence.Item `json:"evidence,omitempty"`
+	Notes         []string        `json:"notes,omitempty"`
+	Provenance    Provenance      `json:"provenance"`
+	WrittenAt     time.Time       `json:"written_at"`
+}
+
+var (
+	jsonNameRe     = regexp.MustCompile(`^pr-(\d+)\.card\.json$`)
+	markdownNameRe = regexp.MustCompile(`^pr-(\d+)\.md$`)
+	// renderedCheckRe is the verification mark Render appends to evidence.
+	renderedCheckRe = regexp.MustCompile(` — (verified|unverified \(.*\))$`)
+)
+
+func JSONPath(cardDir string, pr int) string {
+	return filepath.Join(cardDir, fmt.Sprintf("pr-%d.card.json", pr))
+}
+
+func MarkdownPath(cardDir string, pr int) string {
+	return filepath.Join(cardDir, fmt.Sprintf("pr-%d.md", pr))
+}
+
+// Read loads a card sidecar.
+func Read(path string) (Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Record{}, fmt.Errorf("read card: %w", err)
+	}
+	rec := Record{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return Record{}, fmt.Errorf("parse card %s: %w", path, err)
+	}
+	switch {
+	case rec.SchemaVersion == 0:
+		return Record{}, fmt.Errorf("card %s: missing schema_version", path)
+	case rec.SchemaVersion > SchemaVersion:
+		return Record{}, fmt.Errorf("card %s: schema_version %d is newer than this build (%d)", path, rec.SchemaVersion, SchemaVersion)
+	}
+	if rec.PR <= 0 {
+		return Record{}, fmt.Errorf("card %s: missing pr", path)
+	}
+	return rec, nil
+}
+
+// ReadDir loads every card sidecar in cardDir, ordered by PR. A Markdown card
+// without a sidecar is an error pointing at `triage cards --migrate`.
+func ReadDir(cardDir string) ([]Record, error) {
+	entries, err := os.ReadDir(cardDir)
+	if err != nil {
+		return nil, fmt.Errorf("read cards dir: %w", err)
+	}
+	names := map[string]bool{}
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	records := []Record{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if m := markdownNameRe.FindStringSubmatch(entry.Name()); m != nil {
+			if !names["pr-"+m[1]+".card.json"] {
+				return nil, fmt.Errorf("%s has no pr-%s.card.json (run `triage cards --migrate`)", filepath.Join(cardDir, entry.Name()), m[1])
+			}
+			continue
+		}
+		if !jsonNameRe.MatchString(entry.Name()) {
+			continue
+		}
+		rec, err := Read(filepath.Join(cardDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].PR < records[j].PR
+	})
+	return records, nil
+}
+
+// CloseReady reports whether the model marked the card "close-ready: yes".
+func (r Record) CloseReady() bool {
+	for _, note := range r.Notes {
+		value := strings.ToLower(strings.TrimSpace(note))
+		if strings.HasPrefix(value, "close-ready: yes") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasVerifiedEvidence reports whether at least one evidence quote was found
+// in the file it cites.
+func (r Record) HasVerifiedEvidence() bool {
+	for _, ev := range r.Evidence {
+		if ev.Verified {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrate writes a sidecar for every Markdown card in cardDir that lacks one,
+// recovering the fields from the Markdown. It returns the PRs migrated.
+func Migrate(cardDir string) ([]int, error) {
+	entries, err := os.ReadDir(cardDir)
+	if err != nil {
+		return nil, fmt.Errorf("read cards dir: %w", err)
+	}
+	migrated := []int{}
+	for _, entry := range entries {
+		m := markdownNameRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		pr, _ := strconv.Atoi(m[1])
+		if _, err := os.Stat(JSONPath(cardDir, pr)); err == nil {
+			continue
+		}
+		rec, err := parseMarkdown(filepath.Join(cardDir, entry.Name()))
+		if err != nil {
+			return migrated, err
+		}
+		if _, err := save(cardDir, rec); err != nil {
+			return migrated, err
+		}
+		migrated = append(migrated, pr)
+	}
+	return migrated, nil
+}
+
+// parseMarkdown recovers a record from a card written before sidecars
+// existed. It is only used by Migrate.
+func parseMarkdown(path string) (Record, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Record{}, fmt.Errorf("read card %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Record{}, fmt.Errorf("read card %s: %w", path, err)
+	}
+
+	rec := Record{SchemaVersion: 1, WrittenAt: info.ModTime().UTC()}
+	section := ""
+	summary := []string{}
+	for _, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(raw)
+		header := func(prefix string) (string, bool) {
+			if !strings.HasPrefix(line, prefix) {
+				return "", false
+			}
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix)), true
+		}
+		if section == "" {
+			if v, ok := header("PR: #"); ok {
+				rec.PR, _ = strconv.Atoi(v)
+				continue
+			}
+			if v, ok := header("Author:"); ok {
+				rec.Author = v
+				continue
+			}
+			if v, ok := header("Maintainer:"); ok {
+				rec.Maintainer = v == "yes"
+				continue
+			}
+			if v, ok := header("Label:"); ok {
+				rec.Label = v
+				continue
+			}
+			if v, ok := header("Injection:"); ok {
+				rec.Injection = v == injection.Suspected
+				continue
+			}
+			if v, ok := header("Rubric:"); ok {
+				rec.Provenance.Rubric = v
+				continue
+			}
+			if v, ok := header("Prompt:"); ok {
+				rec.Provenance.Prompt = v
+				continue
+			}
+			if v, ok := header("Model:"); ok {
+				rec.Provenance.Model = v
+				continue
+			}
+			if v, ok := header("Run:"); ok {
+				rec.Provenance.RunID = v
+				continue
+			}
+		}
+		switch {
+		case line == "## Summary":
+			section = "summary"
+		case line == "## Evidence":
+			section = "evidence"
+		case line == "## Notes":
+			section = "notes"
+		case strings.HasPrefix(line, "## "):
+			section = "other"
+		case strings.HasPrefix(line, "-"):
+			item := strings.TrimSpace(strings.TrimPrefix(line, "-"))
+			switch section {
+			case "summary":
+				summary = append(summary, item)
+			case "evidence":
+				rec.Evidence = append(rec.Evidence, evidence.Item{Text: renderedCheckRe.ReplaceAllString(item, "")})
+			case "notes":
+				rec.Notes = append(rec.Notes, item)
+			}
+		}
+	}
+	rec.Summary = strings.Join(summary, " ")
+	if rec.PR <= 0 {
+		return Record{}, fmt.Errorf("missing PR number in %s", path)
+	}
+	if rec.Maintainer {
+		rec.Label, rec.Summary, rec.Evidence = "", "", nil
+	}
+	return rec, nil
+}
@@ -9,21 +9,27 @@ import (
 )
 
 type Config struct {
-	Repo        string
-	Org         string
-	Name        string
-	DataRoot    string
-	RepoDir     string
-	TriageDir   string
-	RawDir      string
-	MapDir      string
-	SweepDir    string
-	ReduceDir   string
-	RubricPath  string
-	Maintainers string
-	StatePath   string
-	SamplePath  string
-	CommentsDir string
+	Repo         string
+	Org          string
+	Name         string
+	DataRoot     string
+	RepoDir      string
+	TriageDir    string
+	RawDir       string
+	MapDir       string
+	SweepDir     string
+	ReduceDir    string
+	EvalDir      string
+	InjectionDir string
+	PromptsDir   string
+	SettingsPath string
+	RubricPath   string
+	Maintainers  string
+	StatePath    string
+	EventsPath   string
+	MetricsPath  string
+	SamplePath   string
+	CommentsDir  string
 }
 
 func Load(repo string) (Config, error) {
@@ -38,7 +44,15 @@ func Load(repo string) (Config, error) {
 		return Config{}, errors.New("XDG_DATA_HOME must be set")
 	}
 
-	dataRoot := filepath.Join(xdg, "github-triage", parts[0], parts[1])
+	return FromDataRoot(filepath.Join(xdg, "github-triage", parts[0], parts[1])), nil
+}
+
+// FromDataRoot builds the config for an existing data root
+// (<xdg>/github-triage/<org>/<repo>); the repo is taken from its last two
+// path elements. Tools that only know the working dir use it.
+func FromDataRoot(dataRoot string) Config {
+	dataRoot = filepath.Clean(dataRoot)
+	parts := []string{filepath.Base(filepath.Dir(dataRoot)), filepath.Base(dataRoot)}
 	repoDir := filepath.Join(dataRoot, "repo")
 	triageDir := filepath.Join(dataRoot, "triage")
 	rawDir := filepath.Join(triageDir, "raw")
@@ -46,28 +60,36 @@ func Load(repo string) (Config, error) {
 	sweepDir := filepath.Join(triageDir, "sweep")
 	reduceDir := filepath.Join(triageDir, "reduce")
 	commentsDir := filepath.Join(triageDir, "comments")
+	evalDir := filepath.Join(triageDir, "eval")
+	injectionDir := filepath.Join(triageDir, "injection")
 
 	return Config{
-		Repo:        repo,
-		Org:         parts[0],
-		Name:        parts[1],
-		DataRoot:    dataRoot,
-		RepoDir:     repoDir,
-		TriageDir:   triageDir,
-		RawDir:      rawDir,
-		MapDir:      mapDir,
-		SweepDir:    sweepDir,
-		ReduceDir:   reduceDir,
-		RubricPath:  filepath.Join(triageDir, "rubric.md"),
-		Maintainers: filepath.Join(triageDir, "maintainers.txt"),
-		StatePath:   filepath.Join(triageDir, "state.json"),
-		SamplePath:  filepath.Join(rawDir, "pr-sample.json"),
-		CommentsDir: commentsDir,
-	}, nil
+		Repo:         parts[0] + "/" + parts[1],
+		Org:          parts[0],
+		Name:         parts[1],
+		DataRoot:     dataRoot,
+		RepoDir:      repoDir,
+		TriageDir:    triageDir,
+		RawDir:       rawDir,
+		MapDir:       mapDir,
+		SweepDir:     sweepDir,
+		ReduceDir:    reduceDir,
+		EvalDir:      evalDir,
+		InjectionDir: injectionDir,
+		PromptsDir:   filepath.Join(triageDir, "prompts"),
+		SettingsPath: filepath.Join(dataRoot, "triage.toml"),
+		RubricPath:   filepath.Join(triageDir, "rubric.md"),
+		Maintainers:  filepath.Join(triageDir, "maintainers.txt"),
+		StatePath:    filepath.Join(triageDir, "state.json"),
+		EventsPath:   filepath.Join(triageDir, "events.jsonl"),
+		MetricsPath:  filepath.Join(triageDir, "metrics.jsonl"),
+		SamplePath:   filepath.Join(rawDir, "pr-sample.json"),
+		CommentsDir:  commentsDir,
+	}
 }
 
 func (c Config) EnsureDirs() error {
-	dirs := []string{c.RepoDir, c.TriageDir, c.RawDir, c.MapDir, c.SweepDir, c.ReduceDir, c.CommentsDir}
+	dirs := []string{c.RepoDir, c.TriageDir, c.RawDir, c.MapDir, c.SweepDir, c.ReduceDir, c.CommentsDir, c.InjectionDir}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return fmt.Errorf("mkdir %s: %w", dir, err)
@@ -99,3 +121,39 @@ func (c Config) RawPRReviewsPath(number int) string {
 func (c Config) RawPRReviewCommentsPath(number int) string {
 	return filepath.Join(c.CommentsDir, fmt.Sprintf("pr-%d.review-comments.json", number))
 }
+
+func (c Config) RawPRDiffPath(number int) string {
+	return filepath.Join(c.RawDir, fmt.Sprintf("pr-%d.diff", number))
+}
+
+func (c Config) InjectionPath(number int) string {
+	return filepath.Join(c.InjectionDir, fmt.Sprintf("pr-%d.injection.json", number))
+}
+
+// PRFileKinds are the cached per-PR files, by the name prompts and the
+// read_pr_file tool use for them.
+var PRFileKinds = []string{"pr", "files", "meta", "comments", "reviews", "review-comments", "diff", "injection"}
+
+// PRFilePath maps a PR file kind to its cached path.
+func (c Config) PRFilePath(pr int, kind string) (string, error) {
+	switch kind {
+	case "pr":
+		return c.RawPRPath(pr), nil
+	case "files":
+		return c.RawPRFilesPath(pr), nil
+	case "meta":
+		return c.RawPRMetaPath(pr), nil
+	case "comments":
+		return c.RawPRCommentsPath(pr), nil
+	case "reviews":
+		return c.RawPRReviewsPath(pr), nil
+	case "review-comments":
+		return c.RawPRReviewCommentsPath(pr), nil
+	case "diff":
+		return c.RawPRDiffPath(pr), nil
+	case "injection":
+		return c.InjectionPath(pr), nil
+	default:
+		return "", fmt.Errorf("invalid kind %q (want %s)", kind, strings.Join(PRFileKinds, "|"))
+	}
+}
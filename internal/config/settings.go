@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Settings is a triage.toml: the per-repo file in the data root or the user
+// file in the config dir (see UserSettingsPath).
+type Settings struct {
+	Project Project `toml:"project"`
+	// Defaults holds flag defaults. Top-level keys apply to every command
+	// with that flag; a table named after a command ([defaults.map],
+	// [defaults.rubric.init]) applies to that command only.
+	Defaults map[string]any `toml:"defaults"`
+	// Taxonomy is an inline label taxonomy; internal/taxonomy decodes it.
+	Taxonomy map[string]any `toml:"taxonomy"`
+	// Areas maps path prefixes ("src/gateway/", "docs/") to code area names
+	// for the inventory's per-area grouping; see internal/inventory.
+	Areas map[string]string `toml:"areas"`
+}
+
+// Project describes the repo to the prompts. Rules are extra repo-specific
+// classification rules appended to the stage prompts.
+type Project struct {
+	Name        string   `toml:"name"`
+	Description string   `toml:"description"`
+	Rules       []string `toml:"rules"`
+}
+
+// UserSettingsPath is the user-wide triage.toml:
+// $XDG_CONFIG_HOME/github-triage/triage.toml (or the OS equivalent).
+func UserSettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "github-triage", "triage.toml"), nil
+}
+
+// Default looks up a flag default for the command path (e.g. ["rubric",
+// "init"]), preferring the most specific table.
+func (s Settings) Default(path []string, flag string) (any, bool) {
+	for depth := len(path); depth >= 0; depth-- {
+		table := s.Defaults
+		for _, name := range path[:depth] {
+			next, ok := table[name].(map[string]any)
+			if !ok {
+				table = nil
+				break
+			}
+			table = next
+		}
+		if value, ok := table[flag]; ok {
+			if _, isTable := value.(map[string]any); !isTable {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// LoadSettings reads triage.toml; a missing file yields zero settings.
+func LoadSettings(path string) (Settings, error) {
+	settings := Settings{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	meta, err := toml.Decode(string(data), &settings)
+	if err != nil {
+		return settings, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			// defaults and taxonomy are free-form here and checked by
+			// their consumers.
+			if key[0] == "defaults" || key[0] == "taxonomy" {
+				continue
+			}
+			keys = append(keys, key.String())
+		}
+		if len(keys) > 0 {
+			return settings, fmt.Errorf("parse %s: unknown keys %s", path, strings.Join(keys, ", "))
+		}
+	}
+	return settings, nil
+}
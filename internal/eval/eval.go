@@ -0,0 +1,405 @@
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/storage"
+)
+
+const (
+	missingLabel    = "(missing)"
+	maintainerLabel = "(none)"
+)
+
+// Gold is one human label from the gold set (one JSON object per line).
+type Gold struct {
+	PR         int    `json:"pr"`
+	Label      string `json:"label"`
+	CloseReady *bool  `json:"close_ready,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+type Result struct {
+	PR             int    `json:"pr"`
+	Gold           string `json:"gold"`
+	Got            string `json:"got"`
+	GoldCloseReady *bool  `json:"gold_close_ready,omitempty"`
+	GotCloseReady  bool   `json:"got_close_ready"`
+	Correct        bool   `json:"correct"`
+}
+
+type Metric struct {
+	TP        int     `json:"tp"`
+	FP        int     `json:"fp"`
+	FN        int     `json:"fn"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+}
+
+type Change struct {
+	PR       int    `json:"pr"`
+	Gold     string `json:"gold"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+type Report struct {
+	RunID       string                    `json:"run_id"`
+	Stage       string                    `json:"stage"`
+	GoldSet     string                    `json:"gold_set,omitempty"`
+	Model       string                    `json:"model"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+	Total       int                       `json:"total"`
+	Correct     int                       `json:"correct"`
+	Labels      []string                  `json:"labels"`
+	Confusion   map[string]map[string]int `json:"confusion"`
+	Slop        Metric                    `json:"slop"`
+	CloseReady  Metric                    `json:"close_ready"`
+	Results     []Result                  `json:"results"`
+	Baseline    string                    `json:"baseline,omitempty"`
+	Regressions []Change                  `json:"regressions,omitempty"`
+	Fixed       []Change                  `json:"fixed,omitempty"`
+}
+
+func LoadGold(path string) ([]Gold, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open gold set: %w", err)
+	}
+	defer file.Close()
+
+	items := []Gold{}
+	seen := map[int]bool{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var item Gold
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		item.Label = strings.TrimSpace(item.Label)
+		if item.PR <= 0 || item.Label == "" {
+			return nil, fmt.Errorf("%s:%d: pr and label are required", path, line)
+		}
+		if seen[item.PR] {
+			return nil, fmt.Errorf("%s:%d: duplicate pr %d", path, line, item.PR)
+		}
+		seen[item.PR] = true
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read gold set: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, errors.New("gold set is empty")
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].PR < items[j].PR
+	})
+	return items, nil
+}
+
+func PRNumbers(gold []Gold) []int {
+	prs := make([]int, 0, len(gold))
+	for _, item := range gold {
+		prs = append(prs, item.PR)
+	}
+	return prs
+}
+
+// Score compares the cards in cardDir with the gold labels. A PR without a
+// card is scored as "(missing)".
+func Score(gold []Gold, cardDir string) Report {
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		GoldSet:     GoldHash(gold),
+		Confusion:   map[string]map[string]int{},
+	}
+	labelSet := map[string]bool{}
+
+	for _, item := range gold {
+		result := Result{PR: item.PR, Gold: item.Label, GoldCloseReady: item.CloseReady, Got: missingLabel}
+		rec, err := card.Read(card.JSONPath(cardDir, item.PR))
+		if err == nil {
+			result.Got = strings.TrimSpace(rec.Label)
+			if rec.Maintainer {
+				result.Got = maintainerLabel
+			}
+			result.GotCloseReady = rec.CloseReady()
+		}
+		if result.Got == "" {
+			result.Got = missingLabel
+		}
+		result.Correct = result.Got == result.Gold
+		if item.CloseReady != nil && *item.CloseReady != result.GotCloseReady {
+			result.Correct = false
+		}
+
+		labelSet[result.Gold] = true
+		labelSet[result.Got] = true
+		if report.Confusion[result.Gold] == nil {
+			report.Confusion[result.Gold] = map[string]int{}
+		}
+		report.Confusion[result.Gold][result.Got]++
+
+		report.Slop = tally(report.Slop, result.Gold == "slop", result.Got == "slop")
+		if item.CloseReady != nil {
+			report.CloseReady = tally(report.CloseReady, *item.CloseReady, result.GotCloseReady)
+		}
+
+		report.Total++
+		if result.Correct {
+			report.Correct++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	report.Slop = finish(report.Slop)
+	report.CloseReady = finish(report.CloseReady)
+	for label := range labelSet {
+		report.Labels = append(report.Labels, label)
+	}
+	sort.Strings(report.Labels)
+	return report
+}
+
+// GoldHash identifies a gold set by its PRs and labels (notes aside), so
+// runs scored against different gold sets are not compared.
+func GoldHash(gold []Gold) string {
+	items := append([]Gold{}, gold...)
+	sort.Slice(items, func(i, j int) bool { return items[i].PR < items[j].PR })
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(fmt.Sprintf("%d %s", item.PR, item.Label))
+		if item.CloseReady != nil {
+			b.WriteString(fmt.Sprintf(" close_ready=%t", *item.CloseReady))
+		}
+		b.WriteString("\n")
+	}
+	return rubric.ShortHash([]byte(b.String()))
+}
+
+// Comparable reports why previous cannot be a baseline for report: a
+// different stage or gold set makes per-PR deltas meaningless.
+func Comparable(report Report, previous Report) error {
+	if previous.Stage != report.Stage {
+		return fmt.Errorf("baseline %s is a %s eval, this run is %s", previous.RunID, previous.Stage, report.Stage)
+	}
+	if previous.GoldSet == "" || previous.GoldSet != report.GoldSet {
+		return fmt.Errorf("baseline %s was scored against a different gold set", previous.RunID)
+	}
+	return nil
+}
+
+// Compare records per-PR regressions (correct before, wrong now) and fixes
+// against a previous run of the same stage and gold set.
+func Compare(report *Report, previous Report) error {
+	if err := Comparable(*report, previous); err != nil {
+		return err
+	}
+	report.Baseline = previous.RunID
+	before := map[int]Result{}
+	for _, result := range previous.Results {
+		before[result.PR] = result
+	}
+	for _, result := range report.Results {
+		prev, ok := before[result.PR]
+		if !ok || prev.Correct == result.Correct {
+			continue
+		}
+		change := Change{PR: result.PR, Gold: result.Gold, Previous: describe(prev), Current: describe(result)}
+		if prev.Correct {
+			report.Regressions = append(report.Regressions, change)
+		} else {
+			report.Fixed = append(report.Fixed, change)
+		}
+	}
+	return nil
+}
+
+func LoadReport(runDir string) (Report, error) {
+	var report Report
+	if err := storage.ReadJSON(filepath.Join(runDir, "report.json"), &report); err != nil {
+		return Report{}, fmt.Errorf("read eval report %s: %w", runDir, err)
+	}
+	return report, nil
+}
+
+// LatestRun returns the run directory under evalDir whose report was
+// generated last among the runs Comparable with report, excluding report's
+// own run. Run ids are free-form (--run-id), so the report's generated_at
+// orders runs, not the directory name. It returns "" when there is none.
+func LatestRun(evalDir string, report Report) (string, error) {
+	entries, err := os.ReadDir(evalDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read eval dir: %w", err)
+	}
+	latest := ""
+	var latestAt time.Time
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == report.RunID {
+			continue
+		}
+		previous := Report{}
+		if err := storage.ReadJSON(filepath.Join(evalDir, entry.Name(), "report.json"), &previous); err != nil {
+			continue
+		}
+		if Comparable(report, previous) != nil {
+			continue
+		}
+		if latest == "" || previous.GeneratedAt.After(latestAt) {
+			latest, latestAt = entry.Name(), previous.GeneratedAt
+		}
+	}
+	if latest == "" {
+		return "", nil
+	}
+	return filepath.Join(evalDir, latest), nil
+}
+
+func WriteReport(runDir string, report Report) error {
+	if err := storage.WriteJSONAtomic(filepath.Join(runDir, "report.json"), report); err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(filepath.Join(runDir, "report.md"), []byte(RenderReport(report)), 0o644)
+}
+
+func RenderReport(report Report) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Eval — %s\n\n", report.RunID))
+	b.WriteString(fmt.Sprintf("- stage: %s\n", report.Stage))
+	if report.Model != "" {
+		b.WriteString(fmt.Sprintf("- model: %s\n", report.Model))
+	}
+	b.WriteString(fmt.Sprintf("- generated: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05 MST")))
+	b.WriteString(fmt.Sprintf("- accuracy: %d/%d (%s)\n\n", report.Correct, report.Total, percent(report.Correct, report.Total)))
+
+	b.WriteString("## Confusion matrix (rows: gold, columns: model)\n\n")
+	b.WriteString("| gold \\ model |")
+	for _, label := range report.Labels {
+		b.WriteString(fmt.Sprintf(" %s |", label))
+	}
+	b.WriteString("\n|---|")
+	for range report.Labels {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+	for _, gold := range report.Labels {
+		row, ok := report.Confusion[gold]
+		if !ok {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("| %s |", gold))
+		for _, got := range report.Labels {
+			b.WriteString(fmt.Sprintf(" %d |", row[got]))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Precision / recall\n")
+	b.WriteString(renderMetric("slop", report.Slop))
+	b.WriteString(renderMetric("close-ready", report.CloseReady))
+	b.WriteString("\n")
+
+	if report.Baseline != "" {
+		b.WriteString(fmt.Sprintf("## Regressions vs %s\n", report.Baseline))
+		writeChanges(&b, report.Regressions)
+		b.WriteString(fmt.Sprintf("## Fixed vs %s\n", report.Baseline))
+		writeChanges(&b, report.Fixed)
+	}
+
+	b.WriteString("## Mismatches\n")
+	mismatches := 0
+	for _, result := range report.Results {
+		if result.Correct {
+			continue
+		}
+		mismatches++
+		b.WriteString(fmt.Sprintf("- #%d — gold %s, model %s\n", result.PR, describeGold(result), describe(result)))
+	}
+	if mismatches == 0 {
+		b.WriteString("- (none)\n")
+	}
+	return b.String()
+}
+
+func writeChanges(b *strings.Builder, changes []Change) {
+	if len(changes) == 0 {
+		b.WriteString("- (none)\n\n")
+		return
+	}
+	for _, change := range changes {
+		b.WriteString(fmt.Sprintf("- #%d — gold %s: %s → %s\n", change.PR, change.Gold, change.Previous, change.Current))
+	}
+	b.WriteString("\n")
+}
+
+func renderMetric(name string, m Metric) string {
+	if m.TP+m.FP+m.FN == 0 {
+		return fmt.Sprintf("- %s: (no gold positives or predictions)\n", name)
+	}
+	return fmt.Sprintf("- %s: precision %.2f, recall %.2f (tp=%d fp=%d fn=%d)\n", name, m.Precision, m.Recall, m.TP, m.FP, m.FN)
+}
+
+func tally(m Metric, want bool, got bool) Metric {
+	switch {
+	case want && got:
+		m.TP++
+	case got:
+		m.FP++
+	case want:
+		m.FN++
+	}
+	return m
+}
+
+func finish(m Metric) Metric {
+	if m.TP+m.FP > 0 {
+		m.Precision = float64(m.TP) / float64(m.TP+m.FP)
+	}
+	if m.TP+m.FN > 0 {
+		m.Recall = float64(m.TP) / float64(m.TP+m.FN)
+	}
+	return m
+}
+
+func describe(result Result) string {
+	if result.GotCloseReady {
+		return result.Got + " (close-ready)"
+	}
+	return result.Got
+}
+
+func describeGold(result Result) string {
+	if result.GoldCloseReady != nil && *result.GoldCloseReady {
+		return result.Gold + " (close-ready)"
+	}
+	return result.Gold
+}
+
+func percent(n int, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
@@ -0,0 +1,225 @@
+// Package evidence checks `"quote" (source)` items, as cards and injection
+// verdicts carry them, against the PR's cached files.
+package evidence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/config"
+)
+
+// Item is one `"quote" (source)` item after verification. Path is the
+// cached file (relative to the data root) the source resolved to; Verified
+// means the quote was found in it.
+type Item struct {
+	Text     string `json:"text"`
+	Quote    string `json:"quote,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Verified bool   `json:"verified"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// UnmarshalJSON also accepts the plain strings card schema v1 stored.
+func (e *Item) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*e = Item{Text: text}
+		return nil
+	}
+	type plain Item
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+var (
+	evidenceItemRe = regexp.MustCompile(`^\s*"(.+)"\s*\(([^()]+)\)\s*$`)
+	sourceSplitRe  = regexp.MustCompile(`[,;\s]+`)
+	lineSuffixRe   = regexp.MustCompile(`(#L\d+(-L?\d+)?|:\d+(-\d+)?)$`)
+	ellipsisRe     = regexp.MustCompile(`\.\.\.|…`)
+	quoteReplacer  = strings.NewReplacer("“", `"`, "”", `"`, "‘", "'", "’", "'")
+)
+
+// minQuote is the shortest quote, and the shortest fragment between
+// ellipses, worth checking (ignoring spaces); shorter ones match almost
+// anything.
+const minQuote = 12
+
+// evidenceKinds are the read_pr_file kinds evidence may cite: the PR's own
+// cached GitHub data. Model-written files (cards, the injection verdict,
+// rubric.md) and other PRs' files are never evidence.
+var evidenceKinds = []string{"pr", "files", "meta", "comments", "reviews", "review-comments", "diff"}
+
+// Verify checks each item's quote against the cached file its source
+// names: one of this PR's evidenceKinds (`raw` means `pr`), by kind or by
+// path, or a file under repo/.
+func Verify(root string, pr int, items []string) []Item {
+	cfg := config.FromDataRoot(root)
+	out := make([]Item, 0, len(items))
+	for _, item := range items {
+		out = append(out, verifyOne(cfg, pr, item))
+	}
+	return out
+}
+
+func verifyOne(cfg config.Config, pr int, text string) Item {
+	ev := Item{Text: text}
+	m := evidenceItemRe.FindStringSubmatch(quoteReplacer.Replace(text))
+	if m == nil {
+		ev.Reason = `not in "quote" (source) form`
+		return ev
+	}
+	ev.Quote, ev.Source = strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+	for _, fragment := range ellipsisRe.Split(ev.Quote, -1) {
+		if len(strings.Join(strings.Fields(fragment), "")) < minQuote {
+			ev.Reason = fmt.Sprintf("quote too short to verify (each part needs %d+ characters)", minQuote)
+			return ev
+		}
+	}
+
+	paths := resolveSource(cfg, pr, ev.Source)
+	if len(paths) == 0 {
+		ev.Reason = fmt.Sprintf("source %q names no cached file", ev.Source)
+		return ev
+	}
+	rels := []string{}
+	for _, path := range paths {
+		rel, _ := filepath.Rel(cfg.DataRoot, path)
+		rels = append(rels, rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if containsQuote(data, path, ev.Quote) {
+			ev.Path, ev.Verified = rel, true
+			return ev
+		}
+	}
+	ev.Path = rels[0]
+	ev.Reason = "quote not found in " + strings.Join(rels, ", ")
+	return ev
+}
+
+// resolveSource maps a source to existing cached files, most specific first.
+func resolveSource(cfg config.Config, pr int, source string) []string {
+	allowed := map[string]string{}
+	for _, kind := range evidenceKinds {
+		path, _ := cfg.PRFilePath(pr, kind)
+		allowed[path] = kind
+	}
+	paths := []string{}
+	seen := map[string]bool{}
+	add := func(path string) {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || seen[path] {
+			return
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	for _, token := range sourceSplitRe.Split(source, -1) {
+		token = strings.Trim(token, "`'\":")
+		token = lineSuffixRe.ReplaceAllString(token, "")
+		if token == "" {
+			continue
+		}
+		kind := strings.ToLower(token)
+		if kind == "raw" {
+			kind = "pr"
+		}
+		if path, err := cfg.PRFilePath(pr, kind); err == nil {
+			if allowed[path] != "" {
+				add(path)
+			}
+			continue
+		}
+		if !strings.ContainsAny(token, "/.") || filepath.IsAbs(token) {
+			continue
+		}
+		for _, base := range []string{cfg.DataRoot, cfg.TriageDir, cfg.RepoDir} {
+			path := filepath.Join(base, filepath.FromSlash(token))
+			if allowed[path] != "" || within(path, cfg.RepoDir) {
+				add(path)
+			}
+		}
+	}
+	return paths
+}
+
+func within(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// containsQuote compares case-insensitively with whitespace collapsed. JSON
+// files are searched by their decoded strings and diffs without +/- markers;
+// an ellipsis in the quote matches any gap.
+func containsQuote(data []byte, path string, quote string) bool {
+	haystacks := []string{string(data)}
+	if strings.HasSuffix(path, ".json") {
+		var value any
+		if err := json.Unmarshal(data, &value); err == nil {
+			haystacks = append(haystacks, strings.Join(jsonStrings(value, nil), "\n"))
+		}
+	}
+	if strings.HasSuffix(path, ".diff") {
+		lines := strings.Split(string(data), "\n")
+		for i, line := range lines {
+			if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") || strings.HasPrefix(line, " ") {
+				lines[i] = line[1:]
+			}
+		}
+		haystacks = append(haystacks, strings.Join(lines, "\n"))
+	}
+
+	// verifyOne has checked every fragment is long enough on its own.
+	fragments := []string{}
+	for _, part := range ellipsisRe.Split(quote, -1) {
+		if part = normalize(part); part != "" {
+			fragments = append(fragments, part)
+		}
+	}
+	for _, haystack := range haystacks {
+		text := normalize(haystack)
+		found := true
+		for _, fragment := range fragments {
+			i := strings.Index(text, fragment)
+			if i < 0 {
+				found = false
+				break
+			}
+			text = text[i+len(fragment):]
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(quoteReplacer.Replace(s)), " "))
+}
+
+func jsonStrings(value any, out []string) []string {
+	switch v := value.(type) {
+	case string:
+		out = append(out, v)
+	case []any:
+		for _, item := range v {
+			out = jsonStrings(item, out)
+		}
+	case map[string]any:
+		for _, item := range v {
+			out = jsonStrings(item, out)
+		}
+	case nil:
+	default:
+		out = append(out, fmt.Sprint(v))
+	}
+	return out
+}
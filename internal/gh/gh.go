@@ -4,12 +4,61 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
+	"sync"
 )
 
+// tokenVars are the environment variables gh authenticates with.
+var tokenVars = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+
+var (
+	holdOnce sync.Once
+	held     []string
+)
+
+// HoldTokens moves the GitHub token variables out of the process environment,
+// so agent processes started afterwards (which inherit it) never see them.
+// Run and Env still hand them to gh.
+func HoldTokens() {
+	holdOnce.Do(func() {
+		for _, name := range tokenVars {
+			if value, ok := os.LookupEnv(name); ok {
+				held = append(held, name+"="+value)
+				_ = os.Unsetenv(name)
+			}
+		}
+	})
+}
+
+// Env is the environment for a gh child: the process environment plus any
+// held tokens.
+func Env() []string {
+	return append(os.Environ(), held...)
+}
+
+// WithoutTokens drops the GitHub token variables from env.
+func WithoutTokens(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		keep := true
+		for _, token := range tokenVars {
+			if name == token {
+				keep = false
+			}
+		}
+		if keep {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
 func Run(ctx context.Context, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Env = Env()
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/joshp123/github-triage/internal/config"
+)
+
+var rawNameRe = regexp.MustCompile(`^pr-(\d+)\.json$`)
+
+// CachedPR is one PR snapshot in triage/raw. State is its current state from
+// state.json, falling back to the snapshot's when ingest has not recorded
+// it; Raw is the snapshot for callers to decode what they need.
+type CachedPR struct {
+	Number int
+	State  string
+	Raw    json.RawMessage
+}
+
+// CachedPRs lists every raw-cached PR in number order. A missing raw dir is
+// an empty cache.
+func CachedPRs(cfg config.Config) ([]CachedPR, error) {
+	state, err := LoadState(cfg.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(cfg.RawDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read raw dir: %w", err)
+	}
+	prs := []CachedPR{}
+	for _, entry := range entries {
+		m := rawNameRe.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+		number, _ := strconv.Atoi(m[1])
+		data, err := os.ReadFile(cfg.RawPRPath(number))
+		if err != nil {
+			return nil, fmt.Errorf("read pr-%d: %w", number, err)
+		}
+		var snapshot struct {
+			State string `json:"state"`
+		}
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("parse pr-%d: %w", number, err)
+		}
+		prs = append(prs, CachedPR{Number: number, State: state.StateOf(number, snapshot.State), Raw: data})
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Number < prs[j].Number })
+	return prs, nil
+}
+
+// OpenPRs is CachedPRs narrowed to the PRs that are still open. Ingest does
+// not refetch PRs that leave the open list, so their snapshots still say
+// open; state.json decides.
+func OpenPRs(cfg config.Config) ([]CachedPR, error) {
+	prs, err := CachedPRs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	open := prs[:0]
+	for _, pr := range prs {
+		if pr.State == "open" {
+			open = append(open, pr)
+		}
+	}
+	return open, nil
+}
@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Event kinds recorded by ingest in triage/events.jsonl.
+const (
+	EventNew      = "new"
+	EventUpdated  = "updated"
+	EventClosed   = "closed"
+	EventMerged   = "merged"
+	EventReopened = "reopened"
+)
+
+// Event is one PR state change seen by an ingest run. From/To are PR states
+// (open, closed, merged); UpdatedAt is the PR's GitHub updatedAt.
+type Event struct {
+	Time      time.Time `json:"time"`
+	PR        int       `json:"pr"`
+	Kind      string    `json:"kind"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	UpdatedAt string    `json:"updated_at,omitempty"`
+}
+
+// stateEvent classifies a transition between two PR states.
+func stateEvent(to string) string {
+	switch to {
+	case "open":
+		return EventReopened
+	case "merged":
+		return EventMerged
+	default:
+		return EventClosed
+	}
+}
+
+// AppendEvents appends events to the log at path.
+func AppendEvents(path string, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open events log: %w", err)
+	}
+	defer file.Close()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("write events log: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadEvents returns the events logged at or after since, oldest first. A
+// missing log has no events.
+func ReadEvents(path string, since time.Time) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open events log: %w", err)
+	}
+	defer file.Close()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("parse events log %s: %w", path, err)
+		}
+		if !event.Time.Before(since) {
+			events = append(events, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read events log: %w", err)
+	}
+	return events, nil
+}
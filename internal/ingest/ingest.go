@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joshp123/github-triage/internal/config"
 	"github.com/joshp123/github-triage/internal/gh"
@@ -20,10 +21,22 @@ type PRState struct {
 	State     string `json:"state"`
 }
 
+// State is triage/state.json: the last state ingest saw for every PR. PRs
+// that drop off the open list are marked closed here without refetching, so
+// it is current where the raw snapshots are not.
 type State struct {
 	PRs map[string]PRState `json:"prs"`
 }
 
+// StateOf is pr's state from state.json (open, closed or merged), else
+// fallback (normalised) when ingest has not seen it.
+func (s State) StateOf(pr int, fallback string) string {
+	if prState, ok := s.PRs[strconv.Itoa(pr)]; ok && prState.State != "" {
+		return prState.State
+	}
+	return normalizeState(fallback)
+}
+
 type PRMeta struct {
 	Reopened      bool   `json:"reopened"`
 	PreviousState string `json:"previous_state"`
@@ -46,7 +59,9 @@ type graphQLPR struct {
 	Body              string `json:"body"`
 	URL               string `json:"url"`
 	State             string `json:"state"`
+	CreatedAt         string `json:"createdAt"`
 	UpdatedAt         string `json:"updatedAt"`
+	MergedAt          string `json:"mergedAt,omitempty"`
 	AuthorAssociation string `json:"authorAssociation"`
 	IsDraft           bool   `json:"isDraft"`
 	Additions         int    `json:"additions"`
@@ -82,14 +97,16 @@ type graphQLResponse struct {
 	} `json:"data"`
 }
 
-func Discover(ctx context.Context, cfg config.Config, limit int, state string) error {
+// Discover refreshes maintainers and writes up to sample PRs to
+// triage/raw/pr-sample.json for the discover prompt.
+func Discover(ctx context.Context, cfg config.Config, limit int, state string, sample int) error {
 	if err := cfg.EnsureDirs(); err != nil {
 		return err
 	}
 	if err := prewarmMaintainers(ctx, cfg); err != nil {
 		return err
 	}
-	return writeSamplePR(ctx, cfg, limit, state)
+	return writeSample(ctx, cfg, limit, state, sample)
 }
 
 func Run(ctx context.Context, cfg config.Config, limit int, state string) error {
@@ -111,7 +128,7 @@ func prewarmMaintainers(ctx context.Context, cfg config.Config) error {
 	return storage.WriteFileAtomic(cfg.Maintainers, out, 0o644)
 }
 
-func writeSamplePR(ctx context.Context, cfg config.Config, limit int, state string) error {
+func writeSample(ctx context.Context, cfg config.Config, limit int, state string, sample int) error {
 	prs, err := listPRs(ctx, cfg, limit, state)
 	if err != nil {
 		return err
@@ -119,7 +136,26 @@ func writeSamplePR(ctx context.Context, cfg config.Config, limit int, state stri
 	if len(prs) == 0 {
 		return fmt.Errorf("no PRs found for %s", cfg.Repo)
 	}
-	return writePRSnapshot(cfg, cfg.SamplePath, prs[0])
+	picked := spread(prs, sample)
+	for _, pr := range picked {
+		if err := writePRFiles(cfg, pr); err != nil {
+			return err
+		}
+	}
+	return storage.WriteJSONAtomic(cfg.SamplePath, picked)
+}
+
+// spread picks n PRs evenly across the list (newest to oldest update), so
+// the sample is not just the most recent burst.
+func spread(prs []graphQLPR, n int) []graphQLPR {
+	if n <= 0 || n >= len(prs) {
+		return prs
+	}
+	out := make([]graphQLPR, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, prs[i*len(prs)/n])
+	}
+	return out
 }
 
 func listPRs(ctx context.Context, cfg config.Config, limit int, state string) ([]graphQLPR, error) {
@@ -148,7 +184,9 @@ query($owner: String!, $name: String!, $first: Int!, $endCursor: String, $filesF
         body
         url
         state
+        createdAt
         updatedAt
+        mergedAt
         authorAssociation
         isDraft
         additions
@@ -253,7 +291,7 @@ func ingestPRs(ctx context.Context, cfg config.Config, limit int, stateFilter st
 		return err
 	}
 
-	state, err := loadState(cfg.StatePath)
+	state, err := LoadState(cfg.StatePath)
 	if err != nil {
 		return err
 	}
@@ -266,10 +304,20 @@ func ingestPRs(ctx context.Context, cfg config.Config, limit int, stateFilter st
 		}
 	}
 
+	now := time.Now().UTC()
+	events := []Event{}
 	for _, pr := range prs {
 		key := strconv.Itoa(pr.Number)
 		currentState := normalizeState(pr.State)
-		prev := state.PRs[key]
+		prev, seen := state.PRs[key]
+		switch {
+		case !seen:
+			events = append(events, Event{Time: now, PR: pr.Number, Kind: EventNew, To: currentState, UpdatedAt: pr.UpdatedAt})
+		case prev.State != currentState:
+			events = append(events, Event{Time: now, PR: pr.Number, Kind: stateEvent(currentState), From: prev.State, To: currentState, UpdatedAt: pr.UpdatedAt})
+		case prev.UpdatedAt != pr.UpdatedAt:
+			events = append(events, Event{Time: now, PR: pr.Number, Kind: EventUpdated, From: currentState, To: currentState, UpdatedAt: pr.UpdatedAt})
+		}
 		prevState := prev.State
 		if prevState == "" {
 			prevState = currentState
@@ -281,7 +329,7 @@ func ingestPRs(ctx context.Context, cfg config.Config, limit int, stateFilter st
 			return err
 		}
 
-		if prev.UpdatedAt == pr.UpdatedAt && prev.State == currentState {
+		if prev.UpdatedAt == pr.UpdatedAt && prev.State == currentState && snapshotComplete(cfg.RawPRPath(pr.Number), currentState) {
 			state.PRs[key] = PRState{UpdatedAt: pr.UpdatedAt, State: currentState}
 			continue
 		}
@@ -296,20 +344,46 @@ func ingestPRs(ctx context.Context, cfg config.Config, limit int, stateFilter st
 	if stateFilter == "open" {
 		for key, prState := range state.PRs {
 			if _, ok := openSet[key]; !ok {
+				if prState.State == "open" {
+					// Gone from the open list: closed or merged, we cannot
+					// tell which without fetching it.
+					num, _ := strconv.Atoi(key)
+					events = append(events, Event{Time: now, PR: num, Kind: EventClosed, From: "open", To: "closed"})
+				}
 				prState.State = "closed"
 				state.PRs[key] = prState
 			}
 		}
 	}
 
-	return saveState(cfg.StatePath, state)
+	if err := saveState(cfg.StatePath, state); err != nil {
+		return err
+	}
+	return AppendEvents(cfg.EventsPath, events)
+}
+
+// snapshotComplete reports whether a cached snapshot has the fields older
+// versions did not fetch (createdAt, and mergedAt for merged PRs); unchanged
+// PRs without them are rewritten once.
+func snapshotComplete(path string, state string) bool {
+	var pr struct {
+		CreatedAt string `json:"createdAt"`
+		MergedAt  string `json:"mergedAt"`
+	}
+	if storage.ReadJSON(path, &pr) != nil || pr.CreatedAt == "" {
+		return false
+	}
+	return state != "merged" || pr.MergedAt != ""
 }
 
 func writePRSnapshot(cfg config.Config, path string, pr graphQLPR) error {
 	if err := storage.WriteJSONAtomic(path, pr); err != nil {
 		return err
 	}
+	return writePRFiles(cfg, pr)
+}
 
+func writePRFiles(cfg config.Config, pr graphQLPR) error {
 	files := make([]string, 0, len(pr.Files.Nodes))
 	for _, file := range pr.Files.Nodes {
 		files = append(files, file.Path)
@@ -322,7 +396,8 @@ func writePRSnapshot(cfg config.Config, path string, pr graphQLPR) error {
 	return storage.WriteJSONAtomic(cfg.RawPRFilesPath(pr.Number), filesPayload)
 }
 
-func loadState(path string) (State, error) {
+// LoadState reads state.json at path; a missing file is an empty state.
+func LoadState(path string) (State, error) {
 	state := State{PRs: map[string]PRState{}}
 	if _, err := os.Stat(path); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
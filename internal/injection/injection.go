@@ -0,0 +1,185 @@
+package injection
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/evidence"
+	"github.com/joshp123/github-triage/internal/storage"
+)
+
+const (
+	Suspected = "suspected"
+	None      = "none"
+)
+
+// Verdict is the pre-pass result for one PR, as the model writes it and as
+// pr-N.injection.json stores it. Evidence is verified against the cached
+// files the same way card evidence is; verdicts written before that decode
+// as unverified items. The Markdown next to it is a view for humans and is
+// never parsed.
+type Verdict struct {
+	PR       int             `json:"pr"`
+	Verdict  string          `json:"verdict"`
+	Evidence []evidence.Item `json:"evidence,omitempty"`
+	Notes    []string        `json:"notes,omitempty"`
+}
+
+// Path is the verdict record for pr under root.
+func Path(root string, pr int) string {
+	return filepath.Join(root, "triage", "injection", fmt.Sprintf("pr-%d.injection.json", pr))
+}
+
+// MarkdownPath is the human-readable view of the verdict for pr.
+func MarkdownPath(root string, pr int) string {
+	return filepath.Join(root, "triage", "injection", fmt.Sprintf("pr-%d.injection.md", pr))
+}
+
+// Write validates in, verifies its evidence and writes
+// triage/injection/pr-N.injection.json plus its Markdown view under root. A
+// suspected verdict needs at least one verified quote. It returns the record
+// path.
+func Write(root string, in Verdict) (string, error) {
+	if in.PR <= 0 {
+		return "", errors.New("--pr must be > 0")
+	}
+	verdict := strings.TrimSpace(in.Verdict)
+	if err := ValidateVerdict(verdict); err != nil {
+		return "", err
+	}
+	texts := make([]string, 0, len(in.Evidence))
+	for _, ev := range in.Evidence {
+		texts = append(texts, ev.Text)
+	}
+	items := evidence.Verify(root, in.PR, trimStrings(texts))
+	if verdict == Suspected {
+		if len(items) == 0 {
+			return "", errors.New("--evidence is required when verdict=suspected")
+		}
+		if !hasVerified(items) {
+			reasons := []string{}
+			for i, ev := range items {
+				reasons = append(reasons, fmt.Sprintf("%d: %s", i+1, ev.Reason))
+			}
+			return "", fmt.Errorf("no evidence could be verified against the cached files (%s); quote text exactly as it appears and name the file as the source", strings.Join(reasons, "; "))
+		}
+	}
+	v := Verdict{PR: in.PR, Verdict: verdict, Evidence: items, Notes: trimStrings(in.Notes)}
+	if err := store(root, v); err != nil {
+		return "", err
+	}
+	return Path(root, in.PR), nil
+}
+
+func store(root string, v Verdict) error {
+	if err := storage.WriteJSONAtomic(Path(root, v.PR), v); err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(MarkdownPath(root, v.PR), []byte(Render(v)), 0o644)
+}
+
+func ValidateVerdict(verdict string) error {
+	switch verdict {
+	case Suspected, None:
+		return nil
+	default:
+		return fmt.Errorf("invalid --verdict %q (want suspected|none)", verdict)
+	}
+}
+
+// Render is the Markdown view of v.
+func Render(v Verdict) string {
+	var b strings.Builder
+	b.WriteString("# PR Injection Scan\n")
+	b.WriteString(fmt.Sprintf("PR: #%d\n", v.PR))
+	b.WriteString(fmt.Sprintf("Injection: %s\n\n", v.Verdict))
+
+	b.WriteString("## Evidence\n")
+	if len(v.Evidence) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, ev := range v.Evidence {
+		if ev.Verified {
+			b.WriteString(fmt.Sprintf("- %s — verified\n", ev.Text))
+		} else {
+			b.WriteString(fmt.Sprintf("- %s — unverified (%s)\n", ev.Text, unverifiedReason(ev)))
+		}
+	}
+	b.WriteString("\n")
+
+	if len(v.Notes) > 0 {
+		b.WriteString("## Notes\n")
+		for _, note := range v.Notes {
+			b.WriteString(fmt.Sprintf("- %s\n", note))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Read decodes the verdict record for pr. A PR that has not been scanned
+// returns os.ErrNotExist.
+func Read(root string, pr int) (Verdict, error) {
+	v := Verdict{}
+	if err := storage.ReadJSON(Path(root, pr), &v); err != nil {
+		return Verdict{}, err
+	}
+	if v.PR != pr || ValidateVerdict(v.Verdict) != nil {
+		return Verdict{}, fmt.Errorf("injection verdict invalid for PR %d", pr)
+	}
+	if v.Verdict == Suspected && len(v.Evidence) == 0 {
+		return Verdict{}, fmt.Errorf("injection verdict for PR %d is suspected without evidence", pr)
+	}
+	return v, nil
+}
+
+// AppendNotes adds notes to the verdict for pr and rewrites it.
+func AppendNotes(root string, pr int, notes []string) error {
+	notes = trimStrings(notes)
+	if len(notes) == 0 {
+		return nil
+	}
+	v, err := Read(root, pr)
+	if err != nil {
+		return err
+	}
+	v.Notes = append(v.Notes, notes...)
+	return store(root, v)
+}
+
+// IsSuspected reports whether the pre-pass flagged pr. Unscanned or
+// unreadable verdicts count as not suspected.
+func IsSuspected(root string, pr int) bool {
+	v, err := Read(root, pr)
+	return err == nil && v.Verdict == Suspected
+}
+
+func hasVerified(items []evidence.Item) bool {
+	for _, ev := range items {
+		if ev.Verified {
+			return true
+		}
+	}
+	return false
+}
+
+// unverifiedReason explains an unverified item; verdicts written before
+// evidence was verified carry no reason.
+func unverifiedReason(ev evidence.Item) string {
+	if ev.Reason == "" {
+		return "not checked"
+	}
+	return ev.Reason
+}
+
+func trimStrings(items []string) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if value := strings.TrimSpace(item); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package inventory
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/storage"
+)
+
+const (
+	// RootArea is the area of files at the top of the repo.
+	RootArea = "(root)"
+	// UnknownArea is the area of a PR with no cached file list.
+	UnknownArea = "(unknown)"
+)
+
+// Areas maps path prefixes to code area names ([areas] in triage.toml). A
+// file belongs to the area of its longest matching prefix; files no prefix
+// matches fall back to their top-level directory.
+type Areas map[string]string
+
+// AreaCount is one area's per-label counts. A PR that touches several areas
+// counts in each.
+type AreaCount struct {
+	Area   string         `json:"area"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+	PRs    []int          `json:"prs"`
+}
+
+// LoadAreas reads the [areas] table from the repo's triage.toml.
+func LoadAreas(root string) (Areas, error) {
+	settings, err := config.LoadSettings(config.FromDataRoot(root).SettingsPath)
+	if err != nil {
+		return nil, err
+	}
+	areas := Areas{}
+	for prefix, area := range settings.Areas {
+		prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "/")
+		if area = strings.TrimSpace(area); prefix != "" && area != "" {
+			areas[prefix] = area
+		}
+	}
+	return areas, nil
+}
+
+// Of returns the area of one repo-relative file path.
+func (a Areas) Of(file string) string {
+	file = strings.TrimPrefix(path.Clean("/"+file), "/")
+	best, area := -1, ""
+	for prefix, name := range a {
+		dir := strings.TrimSuffix(prefix, "/")
+		if (file == dir || strings.HasPrefix(file, dir+"/")) && len(dir) > best {
+			best, area = len(dir), name
+		}
+	}
+	if area != "" {
+		return area
+	}
+	if top, _, ok := strings.Cut(file, "/"); ok {
+		return top
+	}
+	return RootArea
+}
+
+// prAreas returns the sorted areas a PR touches, from
+// triage/raw/pr-N.files.json. Ingest caches at most 50 files per PR, so
+// areas of very large PRs may be incomplete.
+func prAreas(cfg config.Config, areas Areas, pr int) []string {
+	filesPath, err := cfg.PRFilePath(pr, "files")
+	if err != nil {
+		return []string{UnknownArea}
+	}
+	var files struct {
+		Files []string `json:"files"`
+	}
+	if err := storage.ReadJSON(filesPath, &files); err != nil || len(files.Files) == 0 {
+		return []string{UnknownArea}
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, file := range files.Files {
+		if area := areas.Of(file); !seen[area] {
+			seen[area] = true
+			out = append(out, area)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CountAreas tallies items per area and label, largest area first.
+func CountAreas(items []Item) []AreaCount {
+	byArea := map[string]*AreaCount{}
+	for _, item := range items {
+		for _, area := range item.Areas {
+			count := byArea[area]
+			if count == nil {
+				count = &AreaCount{Area: area, Counts: map[string]int{}}
+				byArea[area] = count
+			}
+			count.Total++
+			count.Counts[item.Label]++
+			count.PRs = append(count.PRs, item.PR)
+		}
+	}
+	out := make([]AreaCount, 0, len(byArea))
+	for _, count := range byArea {
+		out = append(out, *count)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Area < out[j].Area
+	})
+	return out
+}
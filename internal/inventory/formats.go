@@ -0,0 +1,228 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Formats are the inventory outputs write-inventory and reduce can write.
+var Formats = []string{"md", "json", "csv", "html"}
+
+// DefaultFormats is what reduce writes unless told otherwise.
+var DefaultFormats = []string{"md", "json"}
+
+// CheckFormats rejects unknown or empty format lists.
+func CheckFormats(formats []string) error {
+	if len(formats) == 0 {
+		return fmt.Errorf("no --format given (want %s)", strings.Join(Formats, ","))
+	}
+	for _, format := range formats {
+		if !contains(Formats, format) {
+			return fmt.Errorf("invalid --format %q (want %s)", format, strings.Join(Formats, ","))
+		}
+	}
+	return nil
+}
+
+func renderFormat(tax taxonomy.Taxonomy, snap Snapshot, repo string, format string) ([]byte, error) {
+	switch format {
+	case "md":
+		return []byte(Render(tax, snap)), nil
+	case "json":
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshal inventory: %w", err)
+		}
+		return append(data, '\n'), nil
+	case "csv":
+		return renderCSV(tax, snap)
+	case "html":
+		return renderHTML(tax, snap, repo)
+	default:
+		return nil, fmt.Errorf("invalid format %q", format)
+	}
+}
+
+// renderCSV writes one row per item in taxonomy order, for spreadsheets.
+// Areas are joined with ";".
+func renderCSV(tax taxonomy.Taxonomy, snap Snapshot) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	rows := [][]string{{"pr", "label", "bucket", "title", "author", "state", "updated_at", "url", "summary", "evidence", "injection", "areas"}}
+	for _, item := range ordered(tax, snap.Items) {
+		rows = append(rows, []string{
+			strconv.Itoa(item.PR), item.Label, tax.Display(item.Label), item.Title, item.Author, item.State,
+			item.UpdatedAt, item.URL, item.Summary, item.Evidence, strconv.FormatBool(item.Injection),
+			strings.Join(item.Areas, ";"),
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// ordered returns items grouped in taxonomy order, PR order within a label.
+func ordered(tax taxonomy.Taxonomy, items []Item) []Item {
+	out := make([]Item, 0, len(items))
+	for _, label := range tax.Names() {
+		for _, item := range items {
+			if item.Label == label {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+var inventoryHTML = template.Must(template.New("inventory").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Inventory — {{.Repo}} — {{.Snap.Date}}</title>
+<style>
+body { font: 14px/1.4 system-ui, sans-serif; margin: 1.5rem; color: #1f2328; }
+h1 { font-size: 1.3rem; margin: 0 0 .3rem; }
+.muted { color: #59636e; }
+.counts span { margin-right: 1rem; }
+.controls { margin: 1rem 0; display: flex; gap: .5rem; }
+.controls input { flex: 1; max-width: 28rem; padding: .3rem .5rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #d0d7de; padding: .35rem .5rem; text-align: left; vertical-align: top; }
+th { cursor: pointer; user-select: none; background: #f6f8fa; position: sticky; top: 0; }
+th[data-dir="asc"]::after { content: " ▲"; }
+th[data-dir="desc"]::after { content: " ▼"; }
+a { color: #0969da; text-decoration: none; }
+.inj { color: #cf222e; font-weight: 600; }
+.overview { max-width: 60rem; white-space: pre-wrap; }
+.areas { width: auto; margin-bottom: 1rem; }
+.areas td.n, .areas th.n { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Inventory — {{.Repo}}</h1>
+<p class="muted">Snapshot {{.Snap.Date}} · generated {{.Snap.GeneratedAt.Format "2006-01-02 15:04"}} UTC · {{len .Snap.Items}} PRs</p>
+<p class="counts">{{range .Counts}}<span><strong>{{.Display}}</strong>: {{.Count}}</span>{{end}}</p>
+{{with .Snap.Overview}}<h2>Overview</h2><div class="overview">{{.}}</div>{{end}}
+{{with .Areas}}<h2>By area</h2>
+<table class="areas">
+<thead><tr><th>Area</th>{{range $.Counts}}<th class="n">{{.Display}}</th>{{end}}<th class="n">Total</th></tr></thead>
+<tbody>{{range .}}<tr><td>{{.Area}}</td>{{range .Counts}}<td class="n">{{.}}</td>{{end}}<td class="n">{{.Total}}</td></tr>
+{{end}}</tbody>
+</table>{{end}}
+<div class="controls">
+<input id="q" type="search" placeholder="Filter by text, #PR or author…" autofocus>
+<select id="label"><option value="">all labels</option>{{range .Counts}}<option value="{{.Label}}">{{.Display}}</option>{{end}}</select>
+<select id="area"><option value="">all areas</option>{{range .Areas}}<option value="{{.Area}}">{{.Area}}</option>{{end}}</select>
+</div>
+<table id="items">
+<thead><tr><th data-type="num">PR</th><th>Label</th><th>Title</th><th>Author</th><th>State</th><th>Updated</th><th>Areas</th><th>Summary</th><th>Evidence</th></tr></thead>
+<tbody>
+{{range .Items}}<tr data-label="{{.Label}}" data-areas="{{.AreaList}}">
+<td data-sort="{{.PR}}"><a href="{{.URL}}">#{{.PR}}</a></td>
+<td>{{.Display}}{{if .Injection}} <span class="inj" title="injection suspected">⚠</span>{{end}}</td>
+<td>{{.Title}}</td><td>{{.Author}}</td><td>{{.State}}</td><td>{{.UpdatedAt}}</td><td>{{.AreaList}}</td><td>{{.Summary}}</td><td class="muted">{{.Evidence}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+<script>
+(function () {
+  var body = document.querySelector("#items tbody");
+  var rows = Array.prototype.slice.call(body.rows);
+  var q = document.getElementById("q"), label = document.getElementById("label"), area = document.getElementById("area");
+  function filter() {
+    var text = q.value.toLowerCase(), want = label.value, where = area.value;
+    rows.forEach(function (row) {
+      var ok = (!want || row.dataset.label === want) &&
+        (!where || row.dataset.areas.split(", ").indexOf(where) !== -1) &&
+        row.textContent.toLowerCase().indexOf(text) !== -1;
+      row.style.display = ok ? "" : "none";
+    });
+  }
+  q.addEventListener("input", filter);
+  label.addEventListener("change", filter);
+  area.addEventListener("change", filter);
+  document.querySelectorAll("#items th").forEach(function (th, col) {
+    th.addEventListener("click", function () {
+      var dir = th.dataset.dir === "asc" ? "desc" : "asc";
+      document.querySelectorAll("#items th").forEach(function (other) { delete other.dataset.dir; });
+      th.dataset.dir = dir;
+      var num = th.dataset.type === "num";
+      rows.sort(function (a, b) {
+        var x = a.cells[col].dataset.sort || a.cells[col].textContent;
+        var y = b.cells[col].dataset.sort || b.cells[col].textContent;
+        var c = num ? x - y : x.localeCompare(y);
+        return dir === "asc" ? c : -c;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// renderHTML writes a standalone page: counts, the overview, per-area counts,
+// and a table of every item that sorts by column and filters by text, label
+// and area.
+func renderHTML(tax taxonomy.Taxonomy, snap Snapshot, repo string) ([]byte, error) {
+	type count struct {
+		Label, Display string
+		Count          int
+	}
+	type row struct {
+		Item
+		Display  string
+		AreaList string
+	}
+	type areaRow struct {
+		Area   string
+		Counts []int // in label order
+		Total  int
+	}
+	counts := []count{}
+	for _, label := range tax.Names() {
+		counts = append(counts, count{Label: label, Display: tax.Display(label), Count: snap.Counts[label]})
+	}
+	rows := []row{}
+	for _, item := range ordered(tax, snap.Items) {
+		rows = append(rows, row{Item: item, Display: tax.Display(item.Label), AreaList: strings.Join(item.Areas, ", ")})
+	}
+	areas := []areaRow{}
+	for _, area := range snap.Areas {
+		r := areaRow{Area: area.Area, Total: area.Total}
+		for _, label := range tax.Names() {
+			r.Counts = append(r.Counts, area.Counts[label])
+		}
+		areas = append(areas, r)
+	}
+	var b bytes.Buffer
+	err := inventoryHTML.Execute(&b, struct {
+		Repo   string
+		Snap   Snapshot
+		Counts []count
+		Items  []row
+		Areas  []areaRow
+	}{repo, snap, counts, rows, areas})
+	if err != nil {
+		return nil, fmt.Errorf("render inventory html: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
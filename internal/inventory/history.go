@@ -0,0 +1,221 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// SnapshotVersion is the schema of triage/reduce/*.json.
+const SnapshotVersion = 1
+
+const dateLayout = "2006-01-02"
+
+// Snapshot is one inventory, as archived in triage/reduce/<date>.json (and
+// current.json). Snapshots are the input to `triage inventory diff`.
+type Snapshot struct {
+	SchemaVersion int            `json:"schema_version"`
+	Date          string         `json:"date"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Counts        map[string]int `json:"counts"`
+	Overview      string         `json:"overview,omitempty"`
+	Items         []Item         `json:"items"`
+	Areas         []AreaCount    `json:"areas,omitempty"`
+	// Name is what the snapshot was loaded as (a date, "current" or a path).
+	Name string `json:"-"`
+}
+
+var snapshotNameRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.json$`)
+
+// ListSnapshots returns the archived snapshot dates under root, oldest first.
+func ListSnapshots(root string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "triage", "reduce"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read reduce dir: %w", err)
+	}
+	dates := []string{}
+	for _, entry := range entries {
+		if m := snapshotNameRe.FindStringSubmatch(entry.Name()); m != nil && !entry.IsDir() {
+			dates = append(dates, m[1])
+		}
+	}
+	sort.Strings(dates)
+	return dates, nil
+}
+
+// LoadSnapshot loads a snapshot by date (2026-01-31), "current", or a path
+// to a snapshot .json file.
+func LoadSnapshot(root string, name string) (Snapshot, error) {
+	path := name
+	switch {
+	case name == "current":
+		path = filepath.Join(root, "triage", "reduce", "current.json")
+	case snapshotNameRe.MatchString(name + ".json"):
+		path = filepath.Join(root, "triage", "reduce", name+".json")
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && path != name {
+		return Snapshot{}, fmt.Errorf("no snapshot %q (see `triage inventory list`)", name)
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("read snapshot: %w", err)
+	}
+	snap := Snapshot{}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	if snap.SchemaVersion == 0 || snap.SchemaVersion > SnapshotVersion {
+		return Snapshot{}, fmt.Errorf("snapshot %s: unsupported schema_version %d", path, snap.SchemaVersion)
+	}
+	snap.Name = name
+	return snap, nil
+}
+
+// Relabel is a PR whose label changed between snapshots.
+type Relabel struct {
+	Item Item
+	From string
+}
+
+// Removed is a PR that closed or left the inventory between snapshots.
+// State is the PR's state (merged, closed, open) or "unknown".
+type Removed struct {
+	Item  Item
+	State string
+}
+
+// Diff is what changed from one snapshot to another.
+type Diff struct {
+	From, To Snapshot
+	New      []Item
+	// Closed went from open to closed or merged, whether or not its card is
+	// still in the newer snapshot.
+	Closed []Removed
+	// Removed left the newer snapshot without closing.
+	Removed   []Removed
+	Relabeled []Relabel
+}
+
+// Compare diffs two snapshots. A PR's state is the one each snapshot
+// recorded; PRs missing from the newer snapshot are looked up in
+// triage/state.json under root, which ingest keeps current.
+func Compare(root string, from Snapshot, to Snapshot) (Diff, error) {
+	diff := Diff{From: from, To: to}
+	state, err := ingest.LoadState(config.FromDataRoot(root).StatePath)
+	if err != nil {
+		return Diff{}, err
+	}
+	before := map[int]Item{}
+	for _, item := range from.Items {
+		before[item.PR] = item
+	}
+	after := map[int]bool{}
+	for _, item := range to.Items {
+		after[item.PR] = true
+		old, ok := before[item.PR]
+		switch {
+		case !ok:
+			diff.New = append(diff.New, item)
+		case old.Label != item.Label:
+			diff.Relabeled = append(diff.Relabeled, Relabel{Item: item, From: old.Label})
+		}
+		if ok && !closed(old.State) && closed(item.State) {
+			diff.Closed = append(diff.Closed, Removed{Item: item, State: item.State})
+		}
+	}
+	for _, item := range from.Items {
+		if after[item.PR] {
+			continue
+		}
+		now := state.StateOf(item.PR, "")
+		if now == "" {
+			now = "unknown"
+		}
+		if closed(now) && !closed(item.State) {
+			diff.Closed = append(diff.Closed, Removed{Item: item, State: now})
+		} else if !closed(now) {
+			diff.Removed = append(diff.Removed, Removed{Item: item, State: now})
+		}
+	}
+	sort.Slice(diff.Closed, func(i, j int) bool { return diff.Closed[i].Item.PR < diff.Closed[j].Item.PR })
+	return diff, nil
+}
+
+func closed(state string) bool {
+	return state == "closed" || state == "merged"
+}
+
+// RenderDiff writes the diff as Markdown: count deltas per bucket in
+// taxonomy order, then new, closed/merged, removed and relabeled PRs.
+func RenderDiff(tax taxonomy.Taxonomy, diff Diff) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Inventory Diff — %s → %s\n\n", diff.From.Name, diff.To.Name))
+
+	labels := tax.Names()
+	known := map[string]bool{}
+	for _, label := range labels {
+		known[label] = true
+	}
+	for _, counts := range []map[string]int{diff.From.Counts, diff.To.Counts} {
+		extra := []string{}
+		for label := range counts {
+			if !known[label] {
+				known[label] = true
+				extra = append(extra, label)
+			}
+		}
+		sort.Strings(extra)
+		labels = append(labels, extra...)
+	}
+	b.WriteString("## Counts\n")
+	for _, label := range labels {
+		a, z := diff.From.Counts[label], diff.To.Counts[label]
+		b.WriteString(fmt.Sprintf("- %s: %d → %d (%+d)\n", tax.Display(label), a, z, z-a))
+	}
+	a, z := len(diff.From.Items), len(diff.To.Items)
+	b.WriteString(fmt.Sprintf("- total: %d → %d (%+d)\n\n", a, z, z-a))
+
+	b.WriteString(fmt.Sprintf("## New (%d)\n", len(diff.New)))
+	if len(diff.New) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, item := range diff.New {
+		b.WriteString(fmt.Sprintf("- #%d [%s] — %s\n", item.PR, tax.Display(item.Label), item.Summary))
+	}
+	b.WriteString(fmt.Sprintf("\n## Closed/merged (%d)\n", len(diff.Closed)))
+	if len(diff.Closed) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, r := range diff.Closed {
+		b.WriteString(fmt.Sprintf("- #%d [%s] — %s — %s\n", r.Item.PR, tax.Display(r.Item.Label), r.State, r.Item.Summary))
+	}
+	b.WriteString(fmt.Sprintf("\n## Changed label (%d)\n", len(diff.Relabeled)))
+	if len(diff.Relabeled) == 0 {
+		b.WriteString("- (none)\n")
+	}
+	for _, r := range diff.Relabeled {
+		b.WriteString(fmt.Sprintf("- #%d %s → %s — %s\n", r.Item.PR, tax.Display(r.From), tax.Display(r.Item.Label), r.Item.Summary))
+	}
+	if len(diff.Removed) > 0 {
+		// Still open (or never ingested): the card was removed, quarantined
+		// or became a maintainer card.
+		b.WriteString(fmt.Sprintf("\n## Left the inventory, not closed (%d)\n", len(diff.Removed)))
+		for _, r := range diff.Removed {
+			b.WriteString(fmt.Sprintf("- #%d [%s] — %s — %s\n", r.Item.PR, tax.Display(r.Item.Label), r.State, r.Item.Summary))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,255 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/injection"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Item is one PR in the inventory: the card's classification plus what the
+// raw cache knows about the PR, with State from triage/state.json. Areas are
+// the code areas its files touch.
+type Item struct {
+	Label    string `json:"label"`
+	PR       int    `json:"pr"`
+	Summary  string `json:"summary"`
+	Evidence string `json:"evidence,omitempty"`
+	// Injection comes from the card or the injection pre-pass.
+	Injection bool     `json:"injection,omitempty"`
+	Title     string   `json:"title,omitempty"`
+	Author    string   `json:"author,omitempty"`
+	State     string   `json:"state,omitempty"`
+	UpdatedAt string   `json:"updated_at,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Areas     []string `json:"areas,omitempty"`
+	// Card is the full map card (JSON output).
+	Card *card.Record `json:"card,omitempty"`
+}
+
+// rawPR is the part of triage/raw/pr-N.json the inventory shows.
+type rawPR struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	State     string `json:"state"`
+	UpdatedAt string `json:"updatedAt"`
+	Author    struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// CardDir is where the inventory reads its cards from, relative to the data
+// root.
+const CardDir = "triage/map"
+
+// Build collects one item per non-maintainer map card, in PR order. The
+// evidence shown is the card's first verified quote (or its first quote when
+// none verified).
+func Build(root string, tax taxonomy.Taxonomy) ([]Item, error) {
+	records, err := card.ReadDir(filepath.Join(root, filepath.FromSlash(CardDir)))
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.FromDataRoot(root)
+	areas, err := LoadAreas(root)
+	if err != nil {
+		return nil, err
+	}
+	state, err := ingest.LoadState(cfg.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	items := []Item{}
+	for _, rec := range records {
+		if rec.Maintainer {
+			continue
+		}
+		item := Item{Label: rec.Label, PR: rec.PR, Summary: rec.Summary, Author: rec.Author}
+		raw := rawPR{}
+		if path, err := cfg.PRFilePath(rec.PR, "pr"); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				_ = json.Unmarshal(data, &raw)
+			}
+		}
+		item.Title, item.URL, item.UpdatedAt = raw.Title, raw.URL, raw.UpdatedAt
+		item.State = state.StateOf(rec.PR, raw.State)
+		if raw.Author.Login != "" {
+			item.Author = raw.Author.Login
+		}
+		if item.URL == "" {
+			item.URL = fmt.Sprintf("https://github.com/%s/pull/%d", cfg.Repo, rec.PR)
+		}
+		for _, ev := range rec.Evidence {
+			if item.Evidence == "" || ev.Verified {
+				item.Evidence = ev.Text
+			}
+			if ev.Verified {
+				break
+			}
+		}
+		if err := Validate(tax, item); err != nil {
+			return nil, fmt.Errorf("card pr-%d: %w (run `triage lint-cards --stage map`)", rec.PR, err)
+		}
+		item.Injection = rec.Injection || injection.IsSuspected(root, rec.PR)
+		item.Areas = prAreas(cfg, areas, rec.PR)
+		rec := rec
+		item.Card = &rec
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Write builds the inventory from the map cards and writes
+// triage/reduce/current.<format> under root for each format, archiving the
+// same snapshot as triage/reduce/<date>.<format>. JSON is always written:
+// `inventory diff` and `report` read it. overview is an optional narrative
+// section (the only part the LLM writes); empty omits it. It returns the
+// current.* paths written.
+func Write(root string, overview string, formats []string) ([]string, int, error) {
+	if err := CheckFormats(formats); err != nil {
+		return nil, 0, err
+	}
+	if !contains(formats, "json") {
+		formats = append(append([]string{}, formats...), "json")
+	}
+	tax, err := taxonomy.Load(root)
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := Build(root, tax)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now().UTC()
+	snap := Snapshot{
+		SchemaVersion: SnapshotVersion,
+		Date:          now.Format(dateLayout),
+		GeneratedAt:   now,
+		Counts:        map[string]int{},
+		Overview:      strings.TrimSpace(overview),
+		Items:         items,
+		Areas:         CountAreas(items),
+	}
+	for _, item := range items {
+		snap.Counts[item.Label]++
+	}
+
+	dir := filepath.Join(root, "triage", "reduce")
+	paths := []string{}
+	for _, format := range formats {
+		data, err := renderFormat(tax, snap, config.FromDataRoot(root).Repo, format)
+		if err != nil {
+			return nil, 0, err
+		}
+		path := filepath.Join(dir, "current."+format)
+		if err := storage.WriteFileAtomic(path, data, 0o644); err != nil {
+			return nil, 0, err
+		}
+		if err := storage.WriteFileAtomic(filepath.Join(dir, snap.Date+"."+format), data, 0o644); err != nil {
+			return nil, 0, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, len(items), nil
+}
+
+// Validate checks an item built from a card.
+func Validate(tax taxonomy.Taxonomy, item Item) error {
+	if err := tax.Validate(item.Label, ""); err != nil {
+		return err
+	}
+	if item.PR <= 0 {
+		return errors.New("pr must be > 0")
+	}
+	if strings.TrimSpace(item.Summary) == "" {
+		return errors.New("summary is required")
+	}
+	return nil
+}
+
+// Render groups items by label in taxonomy order, using display names, after
+// the optional overview, then lists each area's PRs by label.
+func Render(tax taxonomy.Taxonomy, snap Snapshot) string {
+	labels := tax.Names()
+	items, overview := snap.Items, snap.Overview
+	counts := map[string]int{}
+	grouped := map[string][]Item{}
+
+	injected := 0
+	for _, item := range items {
+		if item.Injection {
+			injected++
+		}
+		counts[item.Label]++
+		grouped[item.Label] = append(grouped[item.Label], item)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Inventory Snapshot — %s\n\n", snap.Date))
+
+	b.WriteString("## Counts\n")
+	for _, label := range labels {
+		b.WriteString(fmt.Sprintf("- %s: %d\n", tax.Display(label), counts[label]))
+	}
+	if injected > 0 {
+		b.WriteString(fmt.Sprintf("- injection suspected: %d\n", injected))
+	}
+	b.WriteString("\n")
+
+	if overview != "" {
+		b.WriteString("## Overview\n")
+		b.WriteString(overview + "\n\n")
+	}
+
+	for _, label := range labels {
+		b.WriteString(fmt.Sprintf("## %s\n", tax.Display(label)))
+		items := grouped[label]
+		if len(items) == 0 {
+			b.WriteString("- (none)\n\n")
+			continue
+		}
+		for _, item := range items {
+			line := fmt.Sprintf("- #%d — %s", item.PR, item.Summary)
+			if strings.TrimSpace(item.Evidence) != "" {
+				line = fmt.Sprintf("%s (%s)", line, item.Evidence)
+			}
+			if item.Injection {
+				line += " — injection: suspected"
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(snap.Areas) > 0 {
+		b.WriteString("## By area\n")
+		for _, area := range snap.Areas {
+			b.WriteString(fmt.Sprintf("### %s (%d)\n", area.Area, area.Total))
+			for _, label := range labels {
+				if area.Counts[label] == 0 {
+					continue
+				}
+				refs := []string{}
+				for _, item := range grouped[label] {
+					if contains(item.Areas, area.Area) {
+						refs = append(refs, fmt.Sprintf("#%d", item.PR))
+					}
+				}
+				b.WriteString(fmt.Sprintf("- %s: %d — %s\n", tax.Display(label), area.Counts[label], strings.Join(refs, ", ")))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/storage"
+)
+
+// PartialsDir holds the hierarchical reduce's intermediate files, relative to
+// the data root: batch-NNN.cards.json (the batch input, written by the CLI)
+// and batch-NNN.md (the model's summary of that batch).
+const PartialsDir = "triage/reduce/partials"
+
+// Batch is one shard of the map cards for the reduce pass.
+type Batch struct {
+	ID    int
+	PRs   []int
+	Input string // batch-NNN.cards.json, absolute
+	// Current is true when batch-NNN.md was written from the current input,
+	// so a re-run skips it.
+	Current bool
+}
+
+// batchCard is the slice of a card the batch prompt needs.
+type batchCard struct {
+	PR        int      `json:"pr"`
+	Author    string   `json:"author"`
+	Label     string   `json:"label"`
+	Injection bool     `json:"injection,omitempty"`
+	Summary   string   `json:"summary"`
+	Evidence  []string `json:"evidence,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+type batchInput struct {
+	Batch int         `json:"batch"`
+	Of    int         `json:"of"`
+	Cards []batchCard `json:"cards"`
+}
+
+var (
+	batchFileRe   = regexp.MustCompile(`^batch-(\d+)\.(cards\.json|md)$`)
+	partialHashRe = regexp.MustCompile(`(?m)^Input: (\S+)$`)
+)
+
+// PrepareBatches shards the non-maintainer map cards into batches of size
+// (in PR order), writes each batch's input file and removes files of batches
+// that no longer exist. A batch whose cards did not change keeps its partial.
+func PrepareBatches(root string, size int) ([]Batch, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d (want > 0)", size)
+	}
+	records, err := card.ReadDir(filepath.Join(root, filepath.FromSlash(CardDir)))
+	if err != nil {
+		return nil, err
+	}
+	cards := []batchCard{}
+	for _, rec := range records {
+		if rec.Maintainer {
+			continue
+		}
+		bc := batchCard{PR: rec.PR, Author: rec.Author, Label: rec.Label, Injection: rec.Injection, Summary: rec.Summary, Notes: rec.Notes}
+		for _, ev := range rec.Evidence {
+			bc.Evidence = append(bc.Evidence, ev.Text)
+		}
+		cards = append(cards, bc)
+	}
+
+	count := (len(cards) + size - 1) / size
+	if count == 0 {
+		count = 1 // one empty batch, so the overview still says the pile is empty
+	}
+	dir := filepath.Join(root, filepath.FromSlash(PartialsDir))
+	batches := make([]Batch, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(cards) {
+			end = len(cards)
+		}
+		chunk := cards[min(i*size, len(cards)):end]
+		batch := Batch{ID: i + 1, Input: filepath.Join(dir, batchName(i+1, "cards.json"))}
+		for _, c := range chunk {
+			batch.PRs = append(batch.PRs, c.PR)
+		}
+		if err := storage.WriteJSONAtomic(batch.Input, batchInput{Batch: i + 1, Of: count, Cards: chunk}); err != nil {
+			return nil, err
+		}
+		batch.Current, err = partialCurrent(dir, batch.ID)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read partials dir: %w", err)
+	}
+	for _, entry := range entries {
+		m := batchFileRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		if id, _ := strconv.Atoi(m[1]); id > count {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, fmt.Errorf("remove stale partial: %w", err)
+			}
+		}
+	}
+	return batches, nil
+}
+
+// WritePartial writes the model's summary of one batch, stamped with the
+// hash of the input it summarised.
+func WritePartial(root string, id int, summary string) (string, error) {
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", errors.New("summary is required")
+	}
+	dir := filepath.Join(root, filepath.FromSlash(PartialsDir))
+	hash, err := inputHash(dir, id)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("batch %d has no input (run `triage reduce --overview`): %w", id, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Batch %d\n", id))
+	b.WriteString(fmt.Sprintf("Input: %s\n", hash))
+	b.WriteString(fmt.Sprintf("Written: %s\n\n", time.Now().UTC().Format(time.RFC3339)))
+	b.WriteString(summary + "\n")
+	path := filepath.Join(dir, batchName(id, "md"))
+	if err := storage.WriteFileAtomic(path, []byte(b.String()), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// partialCurrent reports whether batch-NNN.md exists and was written from
+// the batch's current input.
+func partialCurrent(dir string, id int) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, batchName(id, "md")))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read partial: %w", err)
+	}
+	hash, err := inputHash(dir, id)
+	if err != nil {
+		return false, err
+	}
+	m := partialHashRe.FindSubmatch(data)
+	return m != nil && string(m[1]) == hash, nil
+}
+
+// inputHash stamps a partial with the cards of its batch only, so adding or
+// removing another batch (which changes "of") keeps the partial current.
+func inputHash(dir string, id int) (string, error) {
+	input := batchInput{}
+	if err := storage.ReadJSON(filepath.Join(dir, batchName(id, "cards.json")), &input); err != nil {
+		return "", fmt.Errorf("read batch input: %w", err)
+	}
+	data, err := json.Marshal(input.Cards)
+	if err != nil {
+		return "", err
+	}
+	return rubric.ShortHash(data), nil
+}
+
+// PartialCurrent is partialCurrent for a batch under root.
+func PartialCurrent(root string, id int) (bool, error) {
+	return partialCurrent(filepath.Join(root, filepath.FromSlash(PartialsDir)), id)
+}
+
+func batchName(id int, ext string) string {
+	return fmt.Sprintf("batch-%03d.%s", id, ext)
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/tools"
+)
+
+// Backend runs one agent session: a system prompt plus a single user input.
+// The session is expected to produce its output through tool calls; the
+// runner only checks the files it leaves behind.
+// Every backend exposes req.Tools to the model as native tools whose
+// handlers run in this process (pi through its github-triage extension).
+type Backend interface {
+	Name() string
+	Run(ctx context.Context, req Request) error
+}
+
+type Request struct {
+	Prompt       string
+	SystemPrompt string
+	Input        string
+	WorkDir      string
+	Thinking     string
+	Tools        tools.Set
+	// Usage, if set, receives the session's token counts from backends
+	// that report them.
+	Usage *Usage
+}
+
+// Usage accumulates model usage across a runner's sessions. Every backend
+// counts sessions; only openai reports tokens (pi and replay do not).
+type Usage struct {
+	Sessions     atomic.Int64
+	InputTokens  atomic.Int64
+	OutputTokens atomic.Int64
+}
+
+func (u *Usage) add(input int64, output int64) {
+	if u == nil {
+		return
+	}
+	u.InputTokens.Add(input)
+	u.OutputTokens.Add(output)
+}
+
+type BackendConfig struct {
+	Name      string
+	ReplayDir string
+	BaseURL   string
+}
+
+func newBackend(cfg config.Config, provider string, model string, backendCfg BackendConfig) (Backend, error) {
+	switch strings.TrimSpace(strings.ToLower(backendCfg.Name)) {
+	case "", "pi":
+		return newPiBackend(cfg, provider, model)
+	case "openai":
+		return newOpenAIBackend(model, backendCfg.BaseURL)
+	case "replay":
+		return NewReplayBackend(backendCfg.ReplayDir)
+	default:
+		return nil, fmt.Errorf("invalid backend %q (want pi|openai|replay)", backendCfg.Name)
+	}
+}
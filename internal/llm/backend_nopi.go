@@ -0,0 +1,16 @@
+//go:build !pi
+
+package llm
+
+import (
+	"errors"
+
+	"github.com/joshp123/github-triage/internal/config"
+)
+
+// DefaultBackend is --backend's default: pi is not compiled in.
+const DefaultBackend = "openai"
+
+func newPiBackend(cfg config.Config, provider string, model string) (Backend, error) {
+	return nil, errors.New("pi backend not built (build with -tags pi in a go.work that uses a pi-golang checkout, see README); use --backend openai|replay")
+}
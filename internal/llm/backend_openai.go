@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	maxToolTurns         = 40
+)
+
+// openAIBackend speaks the OpenAI-compatible chat completions API with
+// function calling, so hosted OpenAI and local llama.cpp/Ollama servers both
+// work. The API key comes from OPENAI_API_KEY (optional for local servers).
+type openAIBackend struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Client  *http.Client
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    *string        `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Tools    []chatTool    `json:"tools,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func newOpenAIBackend(model string, baseURL string) (Backend, error) {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(os.Getenv("OPENAI_BASE_URL"))
+	}
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	return openAIBackend{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  strings.TrimSpace(os.Getenv("OPENAI_API_KEY")),
+		Model:   model,
+		Client:  &http.Client{},
+	}, nil
+}
+
+func (b openAIBackend) Name() string {
+	return "openai"
+}
+
+func (b openAIBackend) Run(ctx context.Context, req Request) error {
+	system := req.SystemPrompt
+	input := req.Input
+	messages := []chatMessage{
+		{Role: "system", Content: &system},
+		{Role: "user", Content: &input},
+	}
+	chatTools := make([]chatTool, 0, len(req.Tools.Tools))
+	for _, tool := range req.Tools.Tools {
+		chatTools = append(chatTools, chatTool{Type: "function", Function: chatFunction{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+		}})
+	}
+
+	for turn := 0; turn < maxToolTurns; turn++ {
+		msg, err := b.complete(ctx, chatRequest{Model: b.Model, Messages: messages, Tools: chatTools}, req.Usage)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msg)
+		if len(msg.ToolCalls) == 0 {
+			return nil
+		}
+		for _, call := range msg.ToolCalls {
+			result := req.Tools.Call(ctx, call.Function.Name, json.RawMessage(call.Function.Arguments))
+			messages = append(messages, chatMessage{Role: "tool", Content: &result, ToolCallID: call.ID})
+		}
+	}
+	return fmt.Errorf("openai: no final answer after %d tool turns", maxToolTurns)
+}
+
+func (b openAIBackend) complete(ctx context.Context, body chatRequest, usage *Usage) (chatMessage, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return chatMessage{}, fmt.Errorf("marshal chat request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return chatMessage{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if b.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+b.APIKey)
+	}
+
+	resp, err := b.Client.Do(httpReq)
+	if err != nil {
+		return chatMessage{}, fmt.Errorf("openai: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return chatMessage{}, fmt.Errorf("openai: read response: %w", err)
+	}
+
+	var parsed chatResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return chatMessage{}, fmt.Errorf("openai: status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if parsed.Error != nil {
+		return chatMessage{}, fmt.Errorf("openai: status %d: %s", resp.StatusCode, parsed.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return chatMessage{}, fmt.Errorf("openai: status %d", resp.StatusCode)
+	}
+	if parsed.Usage != nil {
+		usage.add(parsed.Usage.PromptTokens, parsed.Usage.CompletionTokens)
+	}
+	if len(parsed.Choices) == 0 {
+		return chatMessage{}, errors.New("openai: empty response")
+	}
+	return parsed.Choices[0].Message, nil
+}
@@ -0,0 +1,188 @@
+//go:build pi
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/gh"
+	pi "github.com/joshp123/pi-golang"
+)
+
+// DefaultBackend is --backend's default: pi, since it is compiled in.
+const DefaultBackend = "pi"
+
+type piBackend struct {
+	Provider string
+	Model    string
+	AgentDir string
+}
+
+func newPiBackend(cfg config.Config, provider string, model string) (Backend, error) {
+	agentDir, err := ensureAgentDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// pi inherits this process's environment; gh gets the token explicitly.
+	gh.HoldTokens()
+	return piBackend{Provider: provider, Model: model, AgentDir: agentDir}, nil
+}
+
+func (b piBackend) Name() string {
+	return "pi"
+}
+
+func (b piBackend) Run(ctx context.Context, req Request) error {
+	// pi gets the stage tools natively through the github-triage extension,
+	// which calls back into this process and switches off pi's own tools
+	// (bash, read, edit, write): the session can only do what its tools do.
+	server, err := sharedPiToolServer()
+	if err != nil {
+		return err
+	}
+	sessionFile, closeSession, err := server.open(ctx, b.AgentDir, req.Tools)
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+
+	opts := pi.DefaultOneShotOptions()
+	opts.AppName = "github-triage"
+	opts.WorkDir = req.WorkDir
+	opts.SystemPrompt = req.SystemPrompt
+	opts.Mode = pi.ModeDragons
+	opts.Dragons = pi.DragonsOptions{
+		Provider: b.Provider,
+		Model:    b.Model,
+		Thinking: req.Thinking,
+	}
+
+	client, err := startPi(opts, sessionFile)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	_, err = client.Run(ctx, req.Input)
+	if err != nil {
+		stderr := strings.TrimSpace(client.Stderr())
+		if stderr != "" {
+			return fmt.Errorf("pi run failed: %w (stderr: %s)", err, stderr)
+		}
+		return err
+	}
+	return nil
+}
+
+// startPi starts pi with the session file in its environment. pi copies the
+// environment at start, so the variable is only set for the duration.
+func startPi(opts pi.OneShotOptions, sessionFile string) (*pi.OneShotClient, error) {
+	piStartMu.Lock()
+	defer piStartMu.Unlock()
+	if err := os.Setenv(piSessionEnv, sessionFile); err != nil {
+		return nil, fmt.Errorf("set %s: %w", piSessionEnv, err)
+	}
+	defer os.Unsetenv(piSessionEnv)
+	return pi.StartOneShot(opts)
+}
+
+type agentSettings struct {
+	DefaultProvider      string   `json:"defaultProvider,omitempty"`
+	DefaultModel         string   `json:"defaultModel,omitempty"`
+	DefaultThinkingLevel string   `json:"defaultThinkingLevel,omitempty"`
+	EnabledModels        []string `json:"enabledModels,omitempty"`
+}
+
+func ensureAgentDir(cfg config.Config) (string, error) {
+	agentDir := filepath.Join(cfg.DataRoot, "pi-agent")
+	if err := os.MkdirAll(agentDir, 0o700); err != nil {
+		return "", fmt.Errorf("mkdir %s: %w", agentDir, err)
+	}
+
+	if err := seedAuth(agentDir); err != nil {
+		return "", err
+	}
+	if err := installExtension(agentDir); err != nil {
+		return "", err
+	}
+
+	settingsPath := filepath.Join(agentDir, "settings.json")
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		settings := agentSettings{
+			DefaultProvider:      "openai-codex",
+			DefaultModel:         "gpt-5.2",
+			DefaultThinkingLevel: "high",
+			EnabledModels:        []string{"openai-codex/gpt-5.2"},
+		}
+		data, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("marshal settings: %w", err)
+		}
+		data = append(data, '\n')
+		if err := os.WriteFile(settingsPath, data, 0o600); err != nil {
+			return "", fmt.Errorf("write settings: %w", err)
+		}
+	}
+
+	if err := os.Setenv("PI_CODING_AGENT_DIR", agentDir); err != nil {
+		return "", fmt.Errorf("set PI_CODING_AGENT_DIR: %w", err)
+	}
+	return agentDir, nil
+}
+
+func seedAuth(agentDir string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	sourceDir := filepath.Join(home, ".pi", "agent")
+	authSource := filepath.Join(sourceDir, "auth.json")
+	authDest := filepath.Join(agentDir, "auth.json")
+	if fileExists(authSource) {
+		if err := copyIfNewer(authSource, authDest, 0o600); err != nil {
+			return err
+		}
+	}
+	oauthSource := filepath.Join(sourceDir, "oauth.json")
+	oauthDest := filepath.Join(agentDir, "oauth.json")
+	if fileExists(oauthSource) {
+		if err := copyIfNewer(oauthSource, oauthDest, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyIfNewer(source string, dest string, mode os.FileMode) error {
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if destInfo, err := os.Stat(dest); err == nil {
+		if !sourceInfo.ModTime().After(destInfo.ModTime()) {
+			return nil
+		}
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dest, data, mode); err != nil {
+		return err
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
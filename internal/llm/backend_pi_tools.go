@@ -0,0 +1,171 @@
+//go:build pi
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/joshp123/github-triage/internal/tools"
+)
+
+// piExtension registers a session's stage tools with pi as native tools and
+// forwards each call to the runner's tool server. pi loads it from the agent
+// dir's extensions/ on startup.
+//
+//go:embed pi_extension.ts
+var piExtension []byte
+
+const (
+	piExtensionName = "github-triage.ts"
+	// piSessionEnv names the session file the extension reads.
+	piSessionEnv = "TRIAGE_TOOLS"
+	maxToolArgs  = 1 << 20
+)
+
+// piSessionFile is what the extension reads: where to call, with which
+// token, and the tools to register.
+type piSessionFile struct {
+	URL   string       `json:"url"`
+	Token string       `json:"token"`
+	Tools []piToolSpec `json:"tools"`
+}
+
+type piToolSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type piSession struct {
+	ctx   context.Context
+	tools tools.Set
+}
+
+// piToolServer serves the tool calls of running pi sessions on loopback. The
+// session token picks the tool set, so a session only reaches the tools (and
+// the policy, card dir and blocked log) the runner gave it.
+type piToolServer struct {
+	url      string
+	mu       sync.Mutex
+	sessions map[string]piSession
+}
+
+var (
+	piServerOnce sync.Once
+	piServer     *piToolServer
+	piServerErr  error
+	// piStartMu serialises pi starts: the session file path reaches the
+	// child through the environment, which is process-wide.
+	piStartMu sync.Mutex
+)
+
+func sharedPiToolServer() (*piToolServer, error) {
+	piServerOnce.Do(func() {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			piServerErr = fmt.Errorf("start tool server: %w", err)
+			return
+		}
+		piServer = &piToolServer{url: "http://" + ln.Addr().String(), sessions: map[string]piSession{}}
+		go func() { _ = http.Serve(ln, piServer) }()
+	})
+	return piServer, piServerErr
+}
+
+func (s *piToolServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/tools/") {
+		http.NotFound(w, r)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	s.mu.Unlock()
+	if token == "" || !ok {
+		http.Error(w, "unknown session", http.StatusForbidden)
+		return
+	}
+	args, err := io.ReadAll(io.LimitReader(r.Body, maxToolArgs))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out := session.tools.Call(session.ctx, strings.TrimPrefix(r.URL.Path, "/tools/"), args)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, out)
+}
+
+// open registers a session and writes its session file under agentDir. The
+// returned func removes both.
+func (s *piToolServer) open(ctx context.Context, agentDir string, set tools.Set) (string, func(), error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	file := piSessionFile{URL: s.url, Token: token, Tools: []piToolSpec{}}
+	for _, tool := range set.Tools {
+		file.Tools = append(file.Tools, piToolSpec{Name: tool.Name, Description: tool.Description, Parameters: tool.Parameters})
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return "", nil, err
+	}
+	path := filepath.Join(agentDir, "sessions", id+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", nil, fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", nil, fmt.Errorf("write session file: %w", err)
+	}
+
+	s.mu.Lock()
+	s.sessions[token] = piSession{ctx: ctx, tools: set}
+	s.mu.Unlock()
+	return path, func() {
+		s.mu.Lock()
+		delete(s.sessions, token)
+		s.mu.Unlock()
+		_ = os.Remove(path)
+	}, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("random: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// installExtension writes the tools extension into the agent dir when it
+// differs from the embedded one.
+func installExtension(agentDir string) error {
+	path := filepath.Join(agentDir, "extensions", piExtensionName)
+	if data, err := os.ReadFile(path); err == nil && bytes.Equal(data, piExtension) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, piExtension, 0o600); err != nil {
+		return fmt.Errorf("write pi extension: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// github-triage tools for pi. The triage runner starts pi with TRIAGE_TOOLS
+// pointing at a session file (tool server URL, session token, tool schemas);
+// this registers those tools natively, forwards each call to the runner
+// (which applies the stage policy and does the work), and leaves them as the
+// only active tools: pi's bash, read, edit and write are switched off, and a
+// call to any other tool is refused and reported so it reaches the card
+// notes. pi runs started without TRIAGE_TOOLS are left alone.
+import { readFileSync } from "node:fs";
+import { Type } from "@sinclair/typebox";
+import type { ExtensionAPI } from "@mariozechner/pi-coding-agent";
+
+type ToolSpec = { name: string; description: string; parameters: Record<string, unknown> };
+type Session = { url: string; token: string; tools: ToolSpec[] };
+
+export default function (pi: ExtensionAPI) {
+	const file = process.env.TRIAGE_TOOLS;
+	if (!file) return;
+	const session: Session = JSON.parse(readFileSync(file, "utf8"));
+	const names = session.tools.map((tool) => tool.name);
+
+	const call = async (name: string, params: unknown) => {
+		const res = await fetch(`${session.url}/tools/${encodeURIComponent(name)}`, {
+			method: "POST",
+			headers: { authorization: `Bearer ${session.token}`, "content-type": "application/json" },
+			body: JSON.stringify(params ?? {}),
+		});
+		const text = await res.text();
+		return res.ok ? text : `error: ${text}`;
+	};
+
+	pi.on("session_start", async () => {
+		pi.setActiveTools(names);
+	});
+	pi.on("tool_call", async (event) => {
+		if (names.includes(event.toolName)) return undefined;
+		// The runner refuses and records tools outside the session's set.
+		return { block: true, reason: await call(event.toolName, event.input) };
+	});
+
+	for (const tool of session.tools) {
+		pi.registerTool({
+			name: tool.name,
+			label: tool.name,
+			description: tool.description,
+			parameters: Type.Unsafe(tool.parameters),
+			async execute(_toolCallId: string, params: unknown) {
+				return { content: [{ type: "text", text: await call(tool.name, params) }], details: {} };
+			},
+		});
+	}
+}
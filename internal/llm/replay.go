@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joshp123/github-triage/internal/policy"
+)
+
+// ReplayStep is one recorded tool call: "bash" runs Command, "error" fails
+// the session, and any other tool name is dispatched to the stage tool of
+// that name with Args. Steps with Attempt > 0 only run on that attempt for
+// the same prompt+input, which lets a recording fail the first attempt and
+// succeed on the retry.
+type ReplayStep struct {
+	Attempt int             `json:"attempt,omitempty"`
+	Tool    string          `json:"tool"`
+	Command string          `json:"command,omitempty"`
+	Args    json.RawMessage `json:"args,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// ReplayBackend replays recorded tool-call sequences instead of calling a
+// model. Recordings live at <dir>/<prompt>/<input>.jsonl, falling back to
+// <dir>/<prompt>/default.jsonl (a scripted fake agent; the input is exported
+// as $TRIAGE_INPUT, and the session's stage, blocked log and PR bind
+// `triage tool`).
+type ReplayBackend struct {
+	Dir string
+
+	mu       *sync.Mutex
+	attempts map[string]int
+}
+
+func NewReplayBackend(dir string) (*ReplayBackend, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, errors.New("replay backend requires --replay-dir")
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("replay dir not found: %s", dir)
+	}
+	return &ReplayBackend{Dir: dir, mu: &sync.Mutex{}, attempts: map[string]int{}}, nil
+}
+
+func (b *ReplayBackend) Name() string {
+	return "replay"
+}
+
+func (b *ReplayBackend) Run(ctx context.Context, req Request) error {
+	steps, err := b.load(req.Prompt, req.Input)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	key := req.Prompt + "/" + req.Input
+	b.attempts[key]++
+	attempt := b.attempts[key]
+	b.mu.Unlock()
+
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if step.Attempt > 0 && step.Attempt != attempt {
+			continue
+		}
+		switch step.Tool {
+		case "error":
+			return fmt.Errorf("replay: %s", step.Error)
+		case "bash":
+			if err := runReplayCommand(ctx, req, step.Command); err != nil {
+				// A failed tool call is reported back to the model, not fatal.
+				logf("replay %s input=%s attempt=%d command failed: %s", req.Prompt, req.Input, attempt, err)
+			}
+		default:
+			// Unknown or disallowed tools are refused (and recorded) like a
+			// live model's call would be.
+			if out := req.Tools.Call(ctx, step.Tool, step.Args); strings.HasPrefix(out, "error: ") {
+				logf("replay %s input=%s attempt=%d tool %s failed: %s", req.Prompt, req.Input, attempt, step.Tool, strings.TrimPrefix(out, "error: "))
+			}
+		}
+	}
+	return nil
+}
+
+func (b *ReplayBackend) load(prompt string, input string) ([]ReplayStep, error) {
+	path := filepath.Join(b.Dir, prompt, input+".jsonl")
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(b.Dir, prompt, "default.jsonl")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: no recording for %s input=%s", prompt, input)
+	}
+	defer file.Close()
+
+	steps := []ReplayStep{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var step ReplayStep
+		if err := json.Unmarshal([]byte(text), &step); err != nil {
+			return nil, fmt.Errorf("replay %s:%d: %w", path, line, err)
+		}
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("replay %s: %w", path, err)
+	}
+	return steps, nil
+}
+
+func runReplayCommand(ctx context.Context, req Request, command string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = req.WorkDir
+	cmd.Env = append(os.Environ(), "TRIAGE_INPUT="+req.Input,
+		policy.StageEnv+"="+req.Tools.Context.Policy.Stage,
+		policy.BlockedLogEnv+"="+req.Tools.Context.BlockedLog,
+		policy.PREnv+"="+strconv.Itoa(req.Tools.Context.PR))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -11,18 +11,34 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/joshp123/github-triage/internal/card"
 	"github.com/joshp123/github-triage/internal/config"
-	pi "github.com/joshp123/pi-golang"
+	"github.com/joshp123/github-triage/internal/injection"
+	"github.com/joshp123/github-triage/internal/inventory"
+	"github.com/joshp123/github-triage/internal/policy"
+	"github.com/joshp123/github-triage/internal/release"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+	"github.com/joshp123/github-triage/internal/tools"
+	"github.com/joshp123/github-triage/prompts"
 )
 
 const (
+	promptTools  = "tools"
 	promptMap    = "map.md"
 	promptSweep  = "sweep.md"
 	promptReduce = "reduce.md"
+	promptBatch  = "reduce-batch.md"
 	promptDisc   = "discover.md"
+	promptInject = "injection.md"
+	promptNotes  = "release-notes.md"
+
+	cardName      = "pr-%d.card.json"
+	injectionName = "pr-%d.injection.json"
 )
 
 type Runner struct {
@@ -30,37 +46,35 @@ type Runner struct {
 	Provider  string
 	Model     string
 	WorkDir   string
-	AgentDir  string
+	Config    config.Config
+	Backend   Backend
+	// RunID is stamped onto every card this runner writes.
+	RunID string
+	// Settings is the repo's triage.toml; its project fields feed the
+	// prompt templates.
+	Settings config.Settings
+	// Thinking, if set, overrides the stage's default thinking level.
+	Thinking string
+	// Taxonomy is the repo's label set (triage/taxonomy.toml or the default).
+	Taxonomy taxonomy.Taxonomy
+	// Usage totals every session this runner (and its copies) ran, for
+	// triage/metrics.jsonl.
+	Usage *Usage
 }
 
-func ResolvePromptDir() (string, error) {
-	start, err := os.Getwd()
+// NewRunner builds a runner. promptDir, if set, overrides prompts ahead of
+// <data-root>/triage/prompts and the embedded defaults.
+func NewRunner(cfg config.Config, model string, promptDir string, backendCfg BackendConfig) (Runner, error) {
+	settings, err := config.LoadSettings(cfg.SettingsPath)
 	if err != nil {
-		return "", err
-	}
-
-	dir := start
-	for {
-		candidate := filepath.Join(dir, "prompts", promptMap)
-		if _, err := os.Stat(candidate); err == nil {
-			return filepath.Join(dir, "prompts"), nil
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
-		}
-		dir = parent
+		return Runner{}, err
 	}
-	return "", errors.New("prompts directory not found; run from repo root")
-}
-
-func NewRunner(cfg config.Config, model string) (Runner, error) {
-	promptDir, err := ResolvePromptDir()
+	tax, err := taxonomy.Load(cfg.DataRoot)
 	if err != nil {
 		return Runner{}, err
 	}
 	provider, resolvedModel := resolveProviderModel(model)
-	agentDir, err := ensureAgentDir(cfg)
+	backend, err := newBackend(cfg, provider, resolvedModel, backendCfg)
 	if err != nil {
 		return Runner{}, err
 	}
@@ -69,7 +83,12 @@ func NewRunner(cfg config.Config, model string) (Runner, error) {
 		Provider:  provider,
 		Model:     resolvedModel,
 		WorkDir:   cfg.DataRoot,
-		AgentDir:  agentDir,
+		Config:    cfg,
+		Backend:   backend,
+		RunID:     time.Now().UTC().Format("20060102T150405Z"),
+		Settings:  settings,
+		Taxonomy:  tax,
+		Usage:     &Usage{},
 	}, nil
 }
 
@@ -91,17 +110,95 @@ func resolveProviderModel(model string) (string, string) {
 	return provider, value
 }
 
+// mapStage is one per-PR stage: its prompt, where each PR's output lands
+// (relative to the data root), and how that output is checked.
+type mapStage struct {
+	Prompt   string
+	Thinking string
+	OutDir   string
+	OutName  string
+	Validate func(path string, pr int) error
+	// AppendNotes adds notes (refused tool calls) to a validated output.
+	AppendNotes func(path string, pr int, notes []string) error
+	// Quarantine, if set, moves an output that never validated out of the
+	// way so skip-existing does not keep it.
+	Quarantine func(path string, pr int, reason error) error
+}
+
+func (r Runner) cardStage(prompt string, thinking string, cardDir string) mapStage {
+	stage := policy.ForStage(promptStage(prompt)).Stage
+	return mapStage{
+		Prompt:   prompt,
+		Thinking: thinking,
+		OutDir:   cardDir,
+		OutName:  cardName,
+		Validate: func(path string, pr int) error {
+			return validateCard(path, pr, r.Taxonomy, stage)
+		},
+		AppendNotes: func(path string, pr int, notes []string) error {
+			return card.AppendNotes(path, notes)
+		},
+		Quarantine: func(path string, pr int, reason error) error {
+			dir := filepath.Dir(path)
+			files := []string{}
+			for _, f := range []string{path, card.MarkdownPath(dir, pr), filepath.Join(dir, fmt.Sprintf("pr-%d.blocked.jsonl", pr))} {
+				if _, err := os.Stat(f); err == nil {
+					files = append(files, f)
+				}
+			}
+			qdir := filepath.Join(r.Config.TriageDir, "quarantine", stage)
+			logf("quarantined pr=%d dir=%s", pr, qdir)
+			return card.Quarantine(qdir, card.Problem{PR: pr, Issues: []string{reason.Error()}, Files: files})
+		},
+	}
+}
+
 func (r Runner) Map(ctx context.Context, cfg config.Config, limit int, prNumbers []int, concurrency int, state string, order string, timeout time.Duration, skipExisting bool) error {
-	cardDir := filepath.Join("triage", "map")
-	return r.runMap(ctx, cfg, filepath.Join(r.PromptDir, promptMap), limit, prNumbers, concurrency, state, order, "high", timeout, skipExisting, true, cardDir)
+	stage := r.cardStage(promptMap, "high", filepath.Join("triage", "map"))
+	return r.runMap(ctx, cfg, stage, limit, prNumbers, concurrency, state, order, timeout, skipExisting, true)
 }
 
 func (r Runner) Sweep(ctx context.Context, cfg config.Config, limit int, prNumbers []int, concurrency int, state string, order string, timeout time.Duration, skipExisting bool) error {
-	cardDir := filepath.Join("triage", "sweep")
-	return r.runMap(ctx, cfg, filepath.Join(r.PromptDir, promptSweep), limit, prNumbers, concurrency, state, order, "low", timeout, skipExisting, false, cardDir)
+	stage := r.cardStage(promptSweep, "low", filepath.Join("triage", "sweep"))
+	return r.runMap(ctx, cfg, stage, limit, prNumbers, concurrency, state, order, timeout, skipExisting, false)
 }
 
-func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string, limit int, prNumbers []int, concurrency int, state string, order string, thinking string, timeout time.Duration, skipExisting bool, abortOnError bool, cardDir string) error {
+// ScanInjection runs the injection pre-pass, writing one verdict per PR to
+// triage/injection. Run it before map/sweep so cards pick up the flag.
+func (r Runner) ScanInjection(ctx context.Context, cfg config.Config, limit int, prNumbers []int, concurrency int, state string, order string, timeout time.Duration, skipExisting bool) error {
+	stage := mapStage{
+		Prompt:   promptInject,
+		Thinking: "low",
+		OutDir:   filepath.Join("triage", "injection"),
+		OutName:  injectionName,
+		Validate: func(path string, pr int) error {
+			if _, err := injection.Read(cfg.DataRoot, pr); err != nil {
+				return fmt.Errorf("injection output invalid for PR %d (expected %s): %w", pr, path, err)
+			}
+			return nil
+		},
+		AppendNotes: func(path string, pr int, notes []string) error {
+			return injection.AppendNotes(cfg.DataRoot, pr, notes)
+		},
+	}
+	return r.runMap(ctx, cfg, stage, limit, prNumbers, concurrency, state, order, timeout, skipExisting, false)
+}
+
+// Classify runs the map or sweep prompt over a fixed PR set into cardDir
+// (relative to the data root). Existing cards are always regenerated and
+// per-PR failures do not abort the run.
+func (r Runner) Classify(ctx context.Context, cfg config.Config, stage string, prNumbers []int, concurrency int, timeout time.Duration, cardDir string) error {
+	switch stage {
+	case "map":
+		return r.runMap(ctx, cfg, r.cardStage(promptMap, "high", cardDir), 0, prNumbers, concurrency, "all", "number-asc", timeout, false, false)
+	case "sweep":
+		return r.runMap(ctx, cfg, r.cardStage(promptSweep, "low", cardDir), 0, prNumbers, concurrency, "all", "number-asc", timeout, false, false)
+	default:
+		return fmt.Errorf("invalid stage %q (want map|sweep)", stage)
+	}
+}
+
+func (r Runner) runMap(ctx context.Context, cfg config.Config, stage mapStage, limit int, prNumbers []int, concurrency int, state string, order string, timeout time.Duration, skipExisting bool, abortOnError bool) error {
 	prs, err := listRawPRs(cfg, limit, prNumbers, state, order)
 	if err != nil {
 		return err
@@ -113,10 +210,17 @@ func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string
 		concurrency = 1
 	}
 
-	restoreEnv := setCardDirEnv(cardDir)
-	defer restoreEnv()
-
-	cardDirAbs := filepath.Join(cfg.DataRoot, cardDir)
+	cardDirAbs := filepath.Join(cfg.DataRoot, stage.OutDir)
+	stagePolicy := policy.ForStage(promptStage(stage.Prompt))
+	promptBytes, err := r.loadPrompt(stage.Prompt)
+	if err != nil {
+		return err
+	}
+	prov := card.Provenance{
+		Prompt: promptStage(stage.Prompt) + "@" + rubric.ShortHash(promptBytes),
+		Model:  r.Provider + "/" + r.Model,
+		RunID:  r.RunID,
+	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -124,13 +228,15 @@ func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string
 	jobs := make(chan int)
 	errCh := make(chan error, 1)
 
+	var wg sync.WaitGroup
 	var errCount int64
 	var successCount int64
 	var skipCount int64
 
 	worker := func() {
+		defer wg.Done()
 		for pr := range jobs {
-			cardPath := filepath.Join(cardDirAbs, fmt.Sprintf("pr-%d.md", pr))
+			cardPath := filepath.Join(cardDirAbs, fmt.Sprintf(stage.OutName, pr))
 			if skipExisting {
 				if _, err := os.Stat(cardPath); err == nil {
 					atomic.AddInt64(&skipCount, 1)
@@ -138,15 +244,21 @@ func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string
 				}
 			}
 
+			// Refused tool calls and commands land next to the output and
+			// are copied into its notes once it validates.
+			blockedLog := filepath.Join(cardDirAbs, fmt.Sprintf("pr-%d.blocked.jsonl", pr))
+			_ = os.Remove(blockedLog)
+			stageTools := tools.ForStage(tools.Context{Config: cfg, CardDir: stage.OutDir, Policy: stagePolicy, BlockedLog: blockedLog, Provenance: prov, PR: pr})
+
 			logf("start pr=%d", pr)
 			var lastErr error
 			for attempt := 1; attempt <= 2; attempt++ {
-				if err := r.runPrompt(ctx, promptPath, strconv.Itoa(pr), thinking, timeout); err != nil {
+				if err := r.runPrompt(ctx, stage.Prompt, strconv.Itoa(pr), stage.Thinking, timeout, stageTools); err != nil {
 					lastErr = err
 					logf("error pr=%d attempt=%d err=%s", pr, attempt, err)
 					continue
 				}
-				if err := validateCard(cardPath, pr); err != nil {
+				if err := stage.Validate(cardPath, pr); err != nil {
 					lastErr = err
 					logf("invalid pr=%d attempt=%d err=%s", pr, attempt, err)
 					continue
@@ -156,6 +268,11 @@ func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string
 			}
 			if lastErr != nil {
 				logf("failed pr=%d err=%s", pr, lastErr)
+				if _, err := os.Stat(cardPath); err == nil && stage.Quarantine != nil {
+					if err := stage.Quarantine(cardPath, pr, lastErr); err != nil {
+						logf("quarantine pr=%d err=%s", pr, err)
+					}
+				}
 				atomic.AddInt64(&errCount, 1)
 				if abortOnError {
 					select {
@@ -167,33 +284,44 @@ func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string
 				}
 				continue
 			}
+			if err := noteBlocked(stage, cardPath, pr, blockedLog); err != nil {
+				logf("blocked notes pr=%d err=%s", pr, err)
+			}
 			atomic.AddInt64(&successCount, 1)
 			logf("done pr=%d", pr)
 		}
 	}
 
 	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
 		go worker()
 	}
 
+feed:
 	for _, pr := range prs {
 		select {
 		case <-ctx.Done():
-			break
+			break feed
 		case jobs <- pr:
 		}
 	}
 	close(jobs)
+	wg.Wait()
 
 	select {
 	case err := <-errCh:
 		return err
 	default:
-		closeReady := countCloseReady(cardDirAbs, prs)
-		logf("summary total=%d success=%d failed=%d skipped=%d close_ready=%d", len(prs), atomic.LoadInt64(&successCount), atomic.LoadInt64(&errCount), atomic.LoadInt64(&skipCount), closeReady)
+		summary := fmt.Sprintf("summary total=%d success=%d failed=%d skipped=%d", len(prs), atomic.LoadInt64(&successCount), atomic.LoadInt64(&errCount), atomic.LoadInt64(&skipCount))
+		if stage.OutName == cardName {
+			summary += fmt.Sprintf(" close_ready=%d", countCloseReady(cardDirAbs, prs))
+		} else {
+			summary += fmt.Sprintf(" suspected=%d", countSuspected(cfg.DataRoot, prs))
+		}
+		logf("%s", summary)
 		if !abortOnError {
 			if atomic.LoadInt64(&successCount) == 0 && atomic.LoadInt64(&errCount) > 0 {
-				return fmt.Errorf("sweep failed for all PRs (%d errors)", errCount)
+				return fmt.Errorf("run failed for all PRs (%d errors)", errCount)
 			}
 			if atomic.LoadInt64(&errCount) > 0 {
 				logf("completed with %d errors", errCount)
@@ -203,53 +331,207 @@ func (r Runner) runMap(ctx context.Context, cfg config.Config, promptPath string
 	}
 }
 
-func (r Runner) Reduce(ctx context.Context) error {
-	promptPath := filepath.Join(r.PromptDir, promptReduce)
-	inventoryPath := filepath.Join(r.WorkDir, "triage", "reduce", "current.md")
+// Reduce writes the inventory's narrative overview hierarchically: the map
+// cards are sharded into batches of batchSize, each batch is summarised into
+// triage/reduce/partials/batch-NNN.md, and the reduce prompt merges the
+// partials into the overview. Partials whose cards did not change are kept,
+// so a re-run only retries failed or stale batches. The counts and grouped
+// items come from the cards (inventory.Write).
+func (r Runner) Reduce(ctx context.Context, batchSize int, timeout time.Duration) error {
+	batches, err := inventory.PrepareBatches(r.WorkDir, batchSize)
+	if err != nil {
+		return err
+	}
 
+	failed := []string{}
+	for _, batch := range batches {
+		if batch.Current {
+			logf("skip batch=%d prs=%d (partial is current)", batch.ID, len(batch.PRs))
+			continue
+		}
+		input := fmt.Sprintf("BATCH %d", batch.ID)
+		logf("start batch=%d/%d prs=%d", batch.ID, len(batches), len(batch.PRs))
+		var lastErr error
+		for attempt := 1; attempt <= 2; attempt++ {
+			if err := r.runPrompt(ctx, promptBatch, input, "medium", timeout, r.stageTools("reduce-batch", input)); err != nil {
+				lastErr = err
+				logf("error batch=%d attempt=%d err=%s", batch.ID, attempt, err)
+				continue
+			}
+			current, err := inventory.PartialCurrent(r.WorkDir, batch.ID)
+			if err == nil && !current {
+				err = fmt.Errorf("batch %d output missing (expected a write_partial call)", batch.ID)
+			}
+			if err != nil {
+				lastErr = err
+				logf("invalid batch=%d attempt=%d err=%s", batch.ID, attempt, err)
+				continue
+			}
+			lastErr = nil
+			break
+		}
+		if lastErr != nil {
+			logf("failed batch=%d err=%s", batch.ID, lastErr)
+			failed = append(failed, strconv.Itoa(batch.ID))
+			continue
+		}
+		logf("done batch=%d", batch.ID)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("reduce batches failed: %s (re-run to retry only those)", strings.Join(failed, ","))
+	}
+
+	inventoryPath := filepath.Join(r.WorkDir, "triage", "reduce", "current.md")
 	var lastErr error
 	for attempt := 1; attempt <= 2; attempt++ {
-		if err := r.runPrompt(ctx, promptPath, "REDUCE", "high", 5*time.Minute); err != nil {
+		if err := r.runPrompt(ctx, promptReduce, "REDUCE", "high", timeout, r.stageTools("reduce", "REDUCE")); err != nil {
 			lastErr = err
 			continue
 		}
-		if _, err := os.Stat(inventoryPath); err != nil {
+		data, err := os.ReadFile(inventoryPath)
+		if err != nil {
 			lastErr = fmt.Errorf("inventory output missing (expected %s)", inventoryPath)
 			continue
 		}
+		if !strings.Contains(string(data), "\n## Overview\n") {
+			lastErr = fmt.Errorf("inventory has no overview (expected a write_inventory call in %s)", inventoryPath)
+			continue
+		}
 		lastErr = nil
 		break
 	}
 	return lastErr
 }
 
+// Discover runs the discover prompt over triage/raw/pr-sample.json; the
+// model must write a new rubric version.
 func (r Runner) Discover(ctx context.Context) error {
-	promptPath := filepath.Join(r.PromptDir, promptDisc)
-	return r.runPrompt(ctx, promptPath, "DISCOVER", "high", 5*time.Minute)
+	before, err := rubric.LatestVersion(r.WorkDir)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := r.runPrompt(ctx, promptDisc, "DISCOVER", "high", 5*time.Minute, r.stageTools("discover", "DISCOVER")); err != nil {
+			lastErr = err
+			continue
+		}
+		after, err := rubric.LatestVersion(r.WorkDir)
+		if err != nil {
+			return err
+		}
+		if after <= before {
+			lastErr = fmt.Errorf("rubric not written (expected %s v%d)", rubric.Path(r.WorkDir), before+1)
+			continue
+		}
+		logf("rubric v%d written", after)
+		lastErr = nil
+		break
+	}
+	return lastErr
 }
 
-func (r Runner) runPrompt(ctx context.Context, promptPath string, input string, thinking string, timeout time.Duration) error {
-	promptBytes, err := os.ReadFile(promptPath)
+// ReleaseNotes runs the release-notes prompt over
+// triage/release-notes/input.json (release.Prepare); the model must write a
+// draft from that input.
+func (r Runner) ReleaseNotes(ctx context.Context, timeout time.Duration) error {
+	var lastErr error
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := r.runPrompt(ctx, promptNotes, "RELEASE-NOTES", "high", timeout, r.stageTools("release-notes", "RELEASE-NOTES")); err != nil {
+			lastErr = err
+			logf("error release-notes attempt=%d err=%s", attempt, err)
+			continue
+		}
+		current, err := release.DraftCurrent(r.WorkDir)
+		if err == nil && !current {
+			err = fmt.Errorf("release notes draft missing (expected a write_release_notes call for %s)", release.DraftPath(r.WorkDir))
+		}
+		if err != nil {
+			lastErr = err
+			logf("invalid release-notes attempt=%d err=%s", attempt, err)
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// loadPrompt reads a stage prompt plus the backend-specific tool
+// instructions, if any, and renders them with the repo context.
+func (r Runner) loadPrompt(name string) ([]byte, error) {
+	dirs := []string{r.PromptDir, r.Config.PromptsDir}
+	promptBytes, _, err := prompts.Read(name, dirs...)
 	if err != nil {
-		return fmt.Errorf("read prompt %s: %w", promptPath, err)
+		return nil, err
+	}
+	if toolBytes, _, err := prompts.Read(promptTools+"/"+r.Backend.Name()+".md", dirs...); err == nil {
+		promptBytes = append(append(promptBytes, '\n'), toolBytes...)
+	}
+	return prompts.Render(name, promptBytes, r.promptData(promptStage(name)))
+}
+
+func (r Runner) promptData(stage string) prompts.Data {
+	project := r.Settings.Project
+	name := strings.TrimSpace(project.Name)
+	if name == "" {
+		name = r.Config.Repo
+	}
+	labels := r.stageLabels(stage)
+	return prompts.Data{
+		Repo:        r.Config.Repo,
+		Org:         r.Config.Org,
+		Name:        r.Config.Name,
+		Project:     name,
+		Description: strings.TrimSpace(project.Description),
+		Rules:       project.Rules,
+		RubricPath:  "triage/rubric.md",
+		Labels:      labelNames(labels),
+		LabelDefs:   labels,
+		Fallback:    fallbackLabel(labels),
+		Stage:       stage,
+		Tools:       policy.ForStage(stage).Tools,
+	}
+}
+
+// stageLabels is the label set a stage's prompt sees: card stages get the
+// labels they may assign, reduce and discover get every label.
+func (r Runner) stageLabels(stage string) []taxonomy.Label {
+	switch stage {
+	case "map", "sweep":
+		return r.Taxonomy.ForStage(stage)
+	default:
+		return r.Taxonomy.Labels
 	}
+}
 
-	opts := pi.DefaultOneShotOptions()
-	opts.AppName = "github-triage"
-	opts.WorkDir = r.WorkDir
-	opts.SystemPrompt = string(promptBytes)
-	opts.Mode = pi.ModeDragons
-	opts.Dragons = pi.DragonsOptions{
-		Provider: r.Provider,
-		Model:    r.Model,
-		Thinking: normalizeThinking(thinking),
+func labelNames(labels []taxonomy.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
 	}
+	return names
+}
 
-	client, err := pi.StartOneShot(opts)
+// fallbackLabel is the name of the label marked fallback, if the stage may
+// assign it.
+func fallbackLabel(labels []taxonomy.Label) string {
+	for _, label := range labels {
+		if label.Fallback {
+			return label.Name
+		}
+	}
+	return ""
+}
+
+func (r Runner) runPrompt(ctx context.Context, prompt string, input string, thinking string, timeout time.Duration, stageTools tools.Set) error {
+	if r.Thinking != "" {
+		thinking = r.Thinking
+	}
+	promptBytes, err := r.loadPrompt(prompt)
 	if err != nil {
 		return err
 	}
-	defer client.Close()
 
 	if timeout <= 0 {
 		timeout = 5 * time.Minute
@@ -257,41 +539,58 @@ func (r Runner) runPrompt(ctx context.Context, promptPath string, input string,
 	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	_, err = client.Run(runCtx, input)
-	if err != nil {
-		stderr := strings.TrimSpace(client.Stderr())
-		if stderr != "" {
-			return fmt.Errorf("pi run failed: %w (stderr: %s)", err, stderr)
-		}
+	if r.Usage != nil {
+		r.Usage.Sessions.Add(1)
+	}
+	return r.Backend.Run(runCtx, Request{
+		Prompt:       promptStage(prompt),
+		SystemPrompt: string(promptBytes),
+		Input:        input,
+		WorkDir:      r.WorkDir,
+		Thinking:     normalizeThinking(thinking),
+		Tools:        stageTools,
+		Usage:        r.Usage,
+	})
+}
+
+// stageTools is the tool set for a single-input stage; refusals are logged
+// under triage/policy/<stage>/.
+func (r Runner) stageTools(stage string, input string) tools.Set {
+	blockedLog := policy.BlockedLog(r.Config, stage, input)
+	_ = os.Remove(blockedLog)
+	return tools.ForStage(tools.Context{Config: r.Config, Policy: policy.ForStage(stage), BlockedLog: blockedLog})
+}
+
+func promptStage(prompt string) string {
+	return strings.TrimSuffix(filepath.Base(prompt), filepath.Ext(prompt))
+}
+
+// noteBlocked appends one "blocked:" note per refused attempt to the stage
+// output.
+func noteBlocked(stage mapStage, path string, pr int, blockedLog string) error {
+	items, err := policy.ReadBlocked(blockedLog)
+	if err != nil || len(items) == 0 {
 		return err
 	}
-	return nil
+	notes := make([]string, 0, len(items))
+	for _, item := range items {
+		notes = append(notes, fmt.Sprintf("blocked: %s (%s)", item.Attempt, item.Reason))
+	}
+	return stage.AppendNotes(path, pr, notes)
 }
 
-func validateCard(path string, pr int) error {
-	data, err := os.ReadFile(path)
+// validateCard lints the card the model wrote, so a card with e.g. unquoted
+// evidence is retried instead of accepted. Errors name stage (map|sweep).
+func validateCard(path string, pr int, tax taxonomy.Taxonomy, stage string) error {
+	rec, err := card.Read(path)
 	if err != nil {
-		return fmt.Errorf("map output missing for PR %d (expected %s)", pr, path)
-	}
-	text := strings.TrimSpace(string(data))
-	lines := strings.Split(text, "\n")
-	if len(lines) < 5 {
-		return fmt.Errorf("map output invalid for PR %d (too short)", pr)
-	}
-	if strings.TrimSpace(lines[0]) != "# PR Classification" {
-		return fmt.Errorf("map output invalid for PR %d (expected '# PR Classification')", pr)
-	}
-	if !strings.HasPrefix(strings.TrimSpace(lines[1]), "PR: #") {
-		return fmt.Errorf("map output invalid for PR %d (missing PR line)", pr)
+		return fmt.Errorf("%s output invalid for PR %d (expected %s): %w", stage, pr, path, err)
 	}
-	if !strings.HasPrefix(strings.TrimSpace(lines[2]), "Author:") {
-		return fmt.Errorf("map output invalid for PR %d (missing Author line)", pr)
+	if rec.PR != pr {
+		return fmt.Errorf("%s output invalid for PR %d (card is for PR %d)", stage, pr, rec.PR)
 	}
-	if !strings.HasPrefix(strings.TrimSpace(lines[3]), "Maintainer:") {
-		return fmt.Errorf("map output invalid for PR %d (missing Maintainer line)", pr)
-	}
-	if !strings.HasPrefix(strings.TrimSpace(lines[4]), "Label:") {
-		return fmt.Errorf("map output invalid for PR %d (missing Label line)", pr)
+	if issues := card.Lint(rec, tax, stage); len(issues) > 0 {
+		return fmt.Errorf("%s output invalid for PR %d: %s", stage, pr, strings.Join(issues, "; "))
 	}
 	return nil
 }
@@ -465,126 +764,23 @@ func logf(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
 }
 
-func setCardDirEnv(dir string) func() {
-	prev, ok := os.LookupEnv("XDG_TRIAGE_CARD_DIR")
-	if strings.TrimSpace(dir) == "" {
-		_ = os.Unsetenv("XDG_TRIAGE_CARD_DIR")
-	} else {
-		_ = os.Setenv("XDG_TRIAGE_CARD_DIR", dir)
-	}
-	return func() {
-		if ok {
-			_ = os.Setenv("XDG_TRIAGE_CARD_DIR", prev)
-			return
+func countSuspected(root string, prs []int) int {
+	count := 0
+	for _, pr := range prs {
+		if injection.IsSuspected(root, pr) {
+			count++
 		}
-		_ = os.Unsetenv("XDG_TRIAGE_CARD_DIR")
 	}
+	return count
 }
 
 func countCloseReady(cardDir string, prs []int) int {
 	count := 0
 	for _, pr := range prs {
-		path := filepath.Join(cardDir, fmt.Sprintf("pr-%d.md", pr))
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-		text := strings.ToLower(string(data))
-		if strings.Contains(text, "close-ready: yes") {
+		rec, err := card.Read(card.JSONPath(cardDir, pr))
+		if err == nil && rec.CloseReady() {
 			count++
 		}
 	}
 	return count
 }
-
-type agentSettings struct {
-	DefaultProvider      string   `json:"defaultProvider,omitempty"`
-	DefaultModel         string   `json:"defaultModel,omitempty"`
-	DefaultThinkingLevel string   `json:"defaultThinkingLevel,omitempty"`
-	EnabledModels        []string `json:"enabledModels,omitempty"`
-}
-
-func ensureAgentDir(cfg config.Config) (string, error) {
-	agentDir := filepath.Join(cfg.DataRoot, "pi-agent")
-	if err := os.MkdirAll(agentDir, 0o700); err != nil {
-		return "", fmt.Errorf("mkdir %s: %w", agentDir, err)
-	}
-
-	if err := seedAuth(agentDir); err != nil {
-		return "", err
-	}
-
-	settingsPath := filepath.Join(agentDir, "settings.json")
-	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		settings := agentSettings{
-			DefaultProvider:      "openai-codex",
-			DefaultModel:         "gpt-5.2",
-			DefaultThinkingLevel: "high",
-			EnabledModels:        []string{"openai-codex/gpt-5.2"},
-		}
-		data, err := json.MarshalIndent(settings, "", "  ")
-		if err != nil {
-			return "", fmt.Errorf("marshal settings: %w", err)
-		}
-		data = append(data, '\n')
-		if err := os.WriteFile(settingsPath, data, 0o600); err != nil {
-			return "", fmt.Errorf("write settings: %w", err)
-		}
-	}
-
-	if err := os.Setenv("PI_CODING_AGENT_DIR", agentDir); err != nil {
-		return "", fmt.Errorf("set PI_CODING_AGENT_DIR: %w", err)
-	}
-	return agentDir, nil
-}
-
-func seedAuth(agentDir string) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-	sourceDir := filepath.Join(home, ".pi", "agent")
-	authSource := filepath.Join(sourceDir, "auth.json")
-	authDest := filepath.Join(agentDir, "auth.json")
-	if fileExists(authSource) {
-		if err := copyIfNewer(authSource, authDest, 0o600); err != nil {
-			return err
-		}
-	}
-	oauthSource := filepath.Join(sourceDir, "oauth.json")
-	oauthDest := filepath.Join(agentDir, "oauth.json")
-	if fileExists(oauthSource) {
-		if err := copyIfNewer(oauthSource, oauthDest, 0o600); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func copyIfNewer(source string, dest string, mode os.FileMode) error {
-	sourceInfo, err := os.Stat(source)
-	if err != nil {
-		return err
-	}
-	if destInfo, err := os.Stat(dest); err == nil {
-		if !sourceInfo.ModTime().After(destInfo.ModTime()) {
-			return nil
-		}
-	}
-	data, err := os.ReadFile(source)
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(dest, data, mode); err != nil {
-		return err
-	}
-	return nil
-}
-
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	if err != nil {
-		return false
-	}
-	return !info.IsDir()
-}
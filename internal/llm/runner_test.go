@@ -0,0 +1,318 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// step builds a replay step calling tool with args.
+func step(attempt int, tool string, args any) ReplayStep {
+	data, _ := json.Marshal(args)
+	return ReplayStep{Attempt: attempt, Tool: tool, Args: data}
+}
+
+// cardArgs is a write_card call for pr whose evidence quotes its raw body.
+func cardArgs(pr int, label string, notes ...string) map[string]any {
+	return map[string]any{
+		"pr":       pr,
+		"author":   "bob",
+		"label":    label,
+		"summary":  fmt.Sprintf("PR %d summary", pr),
+		"evidence": []string{`"widget support for dashboards" (raw)`},
+		"notes":    notes,
+	}
+}
+
+func failStep(attempt int) ReplayStep {
+	return ReplayStep{Attempt: attempt, Tool: "error", Error: "model went away"}
+}
+
+// setup builds a data root with raw snapshots for prs and a replay dir with
+// recordings[prompt][pr].
+func setup(t *testing.T, prs []int, recordings map[string]map[int][]ReplayStep) (config.Config, string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.FromDataRoot(filepath.Join(t.TempDir(), "o", "r"))
+	if err := cfg.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+	for _, pr := range prs {
+		raw := map[string]any{
+			"number":    pr,
+			"state":     "OPEN",
+			"title":     "Add widgets",
+			"body":      "This adds widget support for dashboards.",
+			"updatedAt": "2026-01-01T00:00:00Z",
+			"author":    map[string]string{"login": "bob"},
+		}
+		if err := storage.WriteJSONAtomic(cfg.RawPRPath(pr), raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replayDir := t.TempDir()
+	for prompt, byPR := range recordings {
+		for pr, steps := range byPR {
+			lines := []string{}
+			for _, s := range steps {
+				data, err := json.Marshal(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				lines = append(lines, string(data))
+			}
+			path := filepath.Join(replayDir, prompt, fmt.Sprintf("%d.jsonl", pr))
+			if err := storage.WriteFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return cfg, replayDir
+}
+
+func newTestRunner(t *testing.T, cfg config.Config, replayDir string) Runner {
+	t.Helper()
+	backend, err := NewReplayBackend(replayDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Runner{
+		Provider: "replay",
+		Model:    "test",
+		WorkDir:  cfg.DataRoot,
+		Config:   cfg,
+		Backend:  backend,
+		RunID:    "test",
+		Taxonomy: taxonomy.Default(),
+		Usage:    &Usage{},
+	}
+}
+
+func TestRunMapReplay(t *testing.T) {
+	cases := []struct {
+		name       string
+		stage      string // map|sweep
+		prs        []int
+		recordings map[int][]ReplayStep
+		existing   []int // cards written before the run
+		skip       bool
+		wantErr    string
+		wantCards  []int
+		wantQuar   []int
+		wantNote   map[int]string
+		wantClose  int
+		sessions   int64
+	}{
+		{
+			name:       "map writes a card",
+			stage:      "map",
+			prs:        []int{1},
+			recordings: map[int][]ReplayStep{1: {step(0, "write_card", cardArgs(1, "slop"))}},
+			wantCards:  []int{1},
+			sessions:   1,
+		},
+		{
+			name:  "retry after a failed session",
+			stage: "map",
+			prs:   []int{1},
+			recordings: map[int][]ReplayStep{1: {
+				failStep(1),
+				step(2, "write_card", cardArgs(1, "needs-human")),
+			}},
+			wantCards: []int{1},
+			sessions:  2,
+		},
+		{
+			name:  "retry after a card that fails lint",
+			stage: "sweep",
+			prs:   []int{1},
+			recordings: map[int][]ReplayStep{1: {
+				step(1, "write_card", cardArgs(1, "slop")),
+				step(2, "write_card", cardArgs(1, "slop", "close-ready: yes spam")),
+			}},
+			wantCards: []int{1},
+			wantClose: 1,
+			sessions:  2,
+		},
+		{
+			name:       "sweep label outside the stage is rejected",
+			stage:      "sweep",
+			prs:        []int{1},
+			recordings: map[int][]ReplayStep{1: {step(0, "write_card", cardArgs(1, "good", "close-ready: no"))}},
+			wantErr:    "run failed for all PRs",
+			sessions:   2,
+		},
+		{
+			name:  "map aborts on a PR that never validates",
+			stage: "map",
+			prs:   []int{1},
+			recordings: map[int][]ReplayStep{1: {step(0, "write_card", map[string]any{
+				"pr": 1, "author": "bob", "label": "slop", "summary": "s",
+				"evidence": []string{`"nothing like this is in the PR" (raw)`},
+			})}},
+			wantErr:  "map output invalid for PR 1",
+			sessions: 2,
+		},
+		{
+			name:  "sweep quarantines a card that never lints",
+			stage: "sweep",
+			prs:   []int{1, 2},
+			recordings: map[int][]ReplayStep{
+				1: {step(0, "write_card", cardArgs(1, "slop"))},
+				2: {step(0, "write_card", cardArgs(2, "slop", "close-ready: no"))},
+			},
+			wantCards: []int{2},
+			wantQuar:  []int{1},
+			sessions:  3,
+		},
+		{
+			name:     "skip existing cards",
+			stage:    "sweep",
+			prs:      []int{1, 2},
+			existing: []int{1},
+			skip:     true,
+			recordings: map[int][]ReplayStep{
+				1: {failStep(0)},
+				2: {step(0, "write_card", cardArgs(2, "slop", "close-ready: yes empty"))},
+			},
+			wantCards: []int{1, 2},
+			wantClose: 2,
+			sessions:  1,
+		},
+		{
+			name:  "refused tool call lands in the card notes",
+			stage: "sweep",
+			prs:   []int{1},
+			recordings: map[int][]ReplayStep{1: {
+				step(0, "run_command", map[string]any{"argv": []string{"gh", "pr", "close", "1"}}),
+				step(0, "write_card", cardArgs(1, "needs-human", "close-ready: no")),
+			}},
+			wantCards: []int{1},
+			wantNote:  map[int]string{1: "blocked: tool run_command"},
+			sessions:  1,
+		},
+		{
+			name:  "card for another PR is refused",
+			stage: "sweep",
+			prs:   []int{1},
+			recordings: map[int][]ReplayStep{1: {
+				step(0, "write_card", cardArgs(2, "slop", "close-ready: yes spam")),
+				step(0, "write_card", cardArgs(1, "needs-human", "close-ready: no")),
+			}},
+			wantCards: []int{1},
+			wantNote:  map[int]string{1: "blocked: tool write_card pr=2"},
+			sessions:  1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, replayDir := setup(t, tc.prs, map[string]map[int][]ReplayStep{tc.stage: tc.recordings})
+			cardDir := cfg.MapDir
+			if tc.stage == "sweep" {
+				cardDir = cfg.SweepDir
+			}
+			for _, pr := range tc.existing {
+				in := card.Input{PR: pr, Author: "bob", Label: "slop", Summary: "old", Evidence: []string{`"widget support for dashboards" (raw)`}, Notes: []string{"close-ready: yes old"}}
+				if _, err := card.Write(cfg.DataRoot, cardDir, in, card.Provenance{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := newTestRunner(t, cfg, replayDir)
+			run := r.Map
+			if tc.stage == "sweep" {
+				run = r.Sweep
+			}
+			err := run(context.Background(), cfg, 0, tc.prs, 1, "open", "number-asc", time.Minute, tc.skip)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Fatalf("run: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Fatalf("run error = %v, want %q", err, tc.wantErr)
+			}
+
+			for _, pr := range tc.prs {
+				_, statErr := os.Stat(card.JSONPath(cardDir, pr))
+				if want := contains(tc.wantCards, pr); want != (statErr == nil) {
+					t.Errorf("card for PR %d exists = %v, want %v", pr, statErr == nil, want)
+				}
+				quarantined := filepath.Join(cfg.TriageDir, "quarantine", tc.stage, fmt.Sprintf("pr-%d.lint.txt", pr))
+				data, statErr := os.ReadFile(quarantined)
+				if want := contains(tc.wantQuar, pr); want != (statErr == nil) {
+					t.Errorf("PR %d quarantined = %v, want %v", pr, statErr == nil, want)
+				}
+				if statErr == nil && !strings.Contains(string(data), tc.stage+" output invalid") {
+					t.Errorf("PR %d quarantine reason = %q, want it to name the %s stage", pr, data, tc.stage)
+				}
+			}
+			for pr, want := range tc.wantNote {
+				rec, err := card.Read(card.JSONPath(cardDir, pr))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(strings.Join(rec.Notes, "\n"), want) {
+					t.Errorf("PR %d notes = %q, want one containing %q", pr, rec.Notes, want)
+				}
+			}
+			if got := countCloseReady(cardDir, tc.prs); got != tc.wantClose {
+				t.Errorf("close-ready = %d, want %d", got, tc.wantClose)
+			}
+			if got := r.Usage.Sessions.Load(); got != tc.sessions {
+				t.Errorf("sessions = %d, want %d", got, tc.sessions)
+			}
+		})
+	}
+}
+
+func contains(items []int, value int) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPromptsFollowTaxonomy(t *testing.T) {
+	cfg, replayDir := setup(t, nil, nil)
+	r := newTestRunner(t, cfg, replayDir)
+	r.Taxonomy = taxonomy.Taxonomy{Labels: []taxonomy.Label{
+		{Name: "keep", Description: "Worth a maintainer's time."},
+		{Name: "spam", Display: "junk", Description: "Not worth reading.", Closable: true, Fallback: true},
+	}}
+	for _, name := range []string{"map.md", "sweep.md", "discover.md", "reduce.md", "reduce-batch.md"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := r.loadPrompt(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := string(data)
+			for _, builtin := range []string{"slop", "needs-human", "low-signal", `"good"`, "`good`"} {
+				if strings.Contains(text, builtin) {
+					t.Errorf("prompt names built-in label %s", builtin)
+				}
+			}
+			if !strings.Contains(text, "keep | spam") {
+				t.Errorf("prompt does not list the taxonomy labels")
+			}
+			if name == "map.md" || name == "sweep.md" {
+				for _, want := range []string{"if unsure, choose spam", "`keep`: Worth a maintainer's time."} {
+					if !strings.Contains(text, want) {
+						t.Errorf("prompt lacks %q", want)
+					}
+				}
+			}
+		})
+	}
+}
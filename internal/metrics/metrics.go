@@ -0,0 +1,171 @@
+// Package metrics keeps the time series behind `triage trends`: one point
+// per ingest, map or reduce run in triage/metrics.jsonl, counted from the
+// data root as it stands after the run.
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/queue"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Stages that append a point.
+const (
+	StageIngest = "ingest"
+	StageMap    = "map"
+	StageReduce = "reduce"
+)
+
+// Usage is the model usage of the run that appended a point. Tokens are
+// zero for backends that do not report them (see HasTokens). No backend
+// reports cost, so none is recorded.
+type Usage struct {
+	Backend      string `json:"backend"`
+	Model        string `json:"model"`
+	Sessions     int64  `json:"sessions"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// HasTokens reports whether u's backend counts tokens: openai does, pi and
+// replay do not.
+func (u Usage) HasTokens() bool {
+	return u.Backend == "openai"
+}
+
+// Point is one line of triage/metrics.jsonl.
+type Point struct {
+	Time  time.Time `json:"time"`
+	Stage string    `json:"stage"`
+	// Open counts open PRs in the raw cache; Labels counts their map card
+	// labels (maintainer-authored and uncarded PRs are not in Labels).
+	Open     int            `json:"open"`
+	Labels   map[string]int `json:"labels"`
+	Uncarded int            `json:"uncarded"`
+	// CloseReady counts close-ready sweep cards with a closable label.
+	CloseReady int `json:"close_ready"`
+	// NewAuthors counts distinct authors of open PRs GitHub marks as first
+	// time contributors.
+	NewAuthors int    `json:"new_authors"`
+	Usage      *Usage `json:"usage,omitempty"`
+}
+
+type rawPR struct {
+	AuthorAssociation string `json:"authorAssociation"`
+	Author            struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// Collect counts a point for stage from the data root.
+func Collect(cfg config.Config, tax taxonomy.Taxonomy, stage string, now time.Time) (Point, error) {
+	point := Point{Time: now, Stage: stage, Labels: map[string]int{}}
+	cards := map[int]card.Record{}
+	if _, err := os.Stat(cfg.MapDir); err == nil {
+		records, err := card.ReadDir(cfg.MapDir)
+		if err != nil {
+			return Point{}, err
+		}
+		for _, rec := range records {
+			cards[rec.PR] = rec
+		}
+	}
+
+	open, err := ingest.OpenPRs(cfg)
+	if err != nil {
+		return Point{}, err
+	}
+	newAuthors := map[string]bool{}
+	for _, pr := range open {
+		raw := rawPR{}
+		if err := json.Unmarshal(pr.Raw, &raw); err != nil {
+			return Point{}, fmt.Errorf("parse pr-%d: %w", pr.Number, err)
+		}
+		point.Open++
+		switch raw.AuthorAssociation {
+		case "FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER":
+			newAuthors[raw.Author.Login] = true
+		}
+		rec, ok := cards[pr.Number]
+		switch {
+		case !ok:
+			point.Uncarded++
+		case !rec.Maintainer:
+			point.Labels[rec.Label]++
+		}
+	}
+	point.NewAuthors = len(newAuthors)
+
+	if _, err := os.Stat(cfg.SweepDir); err == nil {
+		q, err := queue.BuildCloseQueue(cfg.SweepDir, tax)
+		if err != nil {
+			return Point{}, err
+		}
+		point.CloseReady = q.CloseReady
+	}
+	return point, nil
+}
+
+// Append adds a point to the log at path.
+func Append(path string, point Point) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.Marshal(point)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open metrics log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write metrics log: %w", err)
+	}
+	return nil
+}
+
+// Read returns the points logged at or after since, oldest first. A missing
+// log has no points.
+func Read(path string, since time.Time) ([]Point, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open metrics log: %w", err)
+	}
+	defer file.Close()
+	points := []Point{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var point Point
+		if err := json.Unmarshal([]byte(text), &point); err != nil {
+			return nil, fmt.Errorf("parse metrics log line %d: %w", line, err)
+		}
+		if !point.Time.Before(since) {
+			points = append(points, point)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read metrics log: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Periods `triage trends` can group points by.
+var Periods = []string{"run", "day", "week"}
+
+// Period is the points of one run, day or week folded into one: counts are
+// the last point's, usage is summed. Untracked counts sessions on backends
+// that report no tokens, so a token total with Untracked > 0 is partial.
+type Period struct {
+	Name      string
+	Point     Point
+	Usage     Usage
+	Untracked int64
+}
+
+// Group folds points (oldest first) into periods.
+func Group(points []Point, by string) ([]Period, error) {
+	var key func(Point) string
+	switch by {
+	case "run":
+		key = func(p Point) string { return p.Time.UTC().Format("2006-01-02 15:04:05") + " " + p.Stage }
+	case "day":
+		key = func(p Point) string { return p.Time.UTC().Format("2006-01-02") }
+	case "week":
+		key = func(p Point) string {
+			year, week := p.Time.UTC().ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}
+	default:
+		return nil, fmt.Errorf("invalid --by %q (want %s)", by, strings.Join(Periods, "|"))
+	}
+	periods := []Period{}
+	for _, point := range points {
+		name := key(point)
+		if by == "run" || len(periods) == 0 || periods[len(periods)-1].Name != name {
+			periods = append(periods, Period{Name: name})
+		}
+		period := &periods[len(periods)-1]
+		period.Point = point
+		if point.Usage != nil {
+			period.Usage.Sessions += point.Usage.Sessions
+			period.Usage.InputTokens += point.Usage.InputTokens
+			period.Usage.OutputTokens += point.Usage.OutputTokens
+			if !point.Usage.HasTokens() {
+				period.Untracked += point.Usage.Sessions
+			}
+		}
+	}
+	return periods, nil
+}
+
+// series is one named row of the trends view.
+type series struct {
+	Name    string
+	Values  []float64
+	Percent bool
+}
+
+// labels returns the labels in taxonomy order, then any others seen.
+func labels(tax taxonomy.Taxonomy, periods []Period) []string {
+	out := tax.Names()
+	known := map[string]bool{}
+	for _, label := range out {
+		known[label] = true
+	}
+	extra := []string{}
+	for _, period := range periods {
+		for label := range period.Point.Labels {
+			if !known[label] {
+				known[label] = true
+				extra = append(extra, label)
+			}
+		}
+	}
+	sort.Strings(extra)
+	return append(out, extra...)
+}
+
+func allSeries(tax taxonomy.Taxonomy, periods []Period) []series {
+	value := func(f func(Period) float64) []float64 {
+		out := make([]float64, len(periods))
+		for i, period := range periods {
+			out[i] = f(period)
+		}
+		return out
+	}
+	out := []series{
+		{Name: "open", Values: value(func(p Period) float64 { return float64(p.Point.Open) })},
+		{Name: "not carded", Values: value(func(p Period) float64 { return float64(p.Point.Uncarded) })},
+	}
+	names := labels(tax, periods)
+	for _, label := range names {
+		label := label
+		out = append(out, series{Name: tax.Display(label), Values: value(func(p Period) float64 { return float64(p.Point.Labels[label]) })})
+	}
+	for _, label := range names {
+		label := label
+		out = append(out, series{Name: tax.Display(label) + " share", Percent: true, Values: value(func(p Period) float64 {
+			carded := 0
+			for _, n := range p.Point.Labels {
+				carded += n
+			}
+			if carded == 0 {
+				return 0
+			}
+			return 100 * float64(p.Point.Labels[label]) / float64(carded)
+		})})
+	}
+	out = append(out,
+		series{Name: "close-ready", Values: value(func(p Period) float64 { return float64(p.Point.CloseReady) })},
+		series{Name: "new authors", Values: value(func(p Period) float64 { return float64(p.Point.NewAuthors) })},
+		series{Name: "model sessions", Values: value(func(p Period) float64 { return float64(p.Usage.Sessions) })},
+		series{Name: "model tokens", Values: value(func(p Period) float64 { return float64(p.Usage.InputTokens + p.Usage.OutputTokens) })},
+	)
+	return out
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as one block character each, scaled between their
+// minimum and maximum.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int(math.Round((v - lo) / (hi - lo) * float64(len(sparks)-1)))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
+
+// Render draws one sparkline per series with its first and last value,
+// for a terminal.
+func Render(tax taxonomy.Taxonomy, periods []Period, by string) string {
+	var b strings.Builder
+	if len(periods) == 0 {
+		return "no metrics in the window (ingest, map and reduce append to triage/metrics.jsonl)\n"
+	}
+	b.WriteString(fmt.Sprintf("%d %s(s): %s → %s\n\n", len(periods), by, periods[0].Name, periods[len(periods)-1].Name))
+	all := allSeries(tax, periods)
+	width := 0
+	for _, s := range all {
+		width = max(width, len([]rune(s.Name)))
+	}
+	format := func(v float64, percent bool) string {
+		if percent {
+			return fmt.Sprintf("%.0f%%", v)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, s := range all {
+		first, last := s.Values[0], s.Values[len(s.Values)-1]
+		pad := strings.Repeat(" ", width-len([]rune(s.Name)))
+		delta := last - first
+		sign := "+"
+		if delta < 0 {
+			sign = "-"
+		}
+		change := sign + format(math.Abs(delta), s.Percent)
+		b.WriteString(fmt.Sprintf("%s%s  %s  %s → %s (%s)\n", s.Name, pad, Sparkline(s.Values), format(first, s.Percent), format(last, s.Percent), change))
+	}
+	var sessions, untracked int64
+	for _, period := range periods {
+		sessions += period.Usage.Sessions
+		untracked += period.Untracked
+	}
+	b.WriteString("\n")
+	if untracked > 0 {
+		b.WriteString(fmt.Sprintf("model tokens are partial: %d of %d sessions ran on a backend that reports none (pi, replay)\n", untracked, sessions))
+	}
+	b.WriteString("model cost: unavailable (no backend reports it)\n")
+	return b.String()
+}
+
+// CSV writes one row per period with every count, for spreadsheets.
+func CSV(tax taxonomy.Taxonomy, periods []Period) ([]byte, error) {
+	names := labels(tax, periods)
+	header := []string{"period", "time", "stage", "open", "uncarded", "close_ready", "new_authors"}
+	header = append(header, names...)
+	header = append(header, "sessions", "untracked_sessions", "input_tokens", "output_tokens")
+	rows := [][]string{header}
+	for _, period := range periods {
+		p := period.Point
+		row := []string{period.Name, p.Time.UTC().Format(time.RFC3339), p.Stage, strconv.Itoa(p.Open), strconv.Itoa(p.Uncarded), strconv.Itoa(p.CloseReady), strconv.Itoa(p.NewAuthors)}
+		for _, label := range names {
+			row = append(row, strconv.Itoa(p.Labels[label]))
+		}
+		row = append(row, strconv.FormatInt(period.Usage.Sessions, 10), strconv.FormatInt(period.Untracked, 10), strconv.FormatInt(period.Usage.InputTokens, 10), strconv.FormatInt(period.Usage.OutputTokens, 10))
+		rows = append(rows, row)
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	return b.Bytes(), nil
+}
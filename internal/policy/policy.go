@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/gh"
+)
+
+// Policy is the per-stage allow-list for what the model may run. PR text is
+// adversarial, so anything not listed here is refused and recorded.
+type Policy struct {
+	Stage    string
+	Tools    []string
+	Commands bool
+}
+
+// Blocked is one refused tool call or command.
+type Blocked struct {
+	Time    time.Time `json:"time"`
+	Stage   string    `json:"stage"`
+	Attempt string    `json:"attempt"`
+	Reason  string    `json:"reason"`
+}
+
+func ForStage(stage string) Policy {
+	switch stage {
+	case "map":
+		return Policy{Stage: stage, Tools: []string{"read_file", "read_pr_file", "write_card", "run_command"}, Commands: true}
+	case "sweep":
+		return Policy{Stage: stage, Tools: []string{"read_file", "read_pr_file", "write_card"}}
+	case "injection":
+		return Policy{Stage: stage, Tools: []string{"read_pr_file", "write_injection"}}
+	case "reduce-batch":
+		return Policy{Stage: stage, Tools: []string{"read_file", "write_partial"}}
+	case "reduce":
+		return Policy{Stage: stage, Tools: []string{"read_file", "write_inventory"}}
+	case "discover":
+		return Policy{Stage: stage, Tools: []string{"read_file", "write_rubric"}}
+	case "release-notes":
+		return Policy{Stage: stage, Tools: []string{"read_file", "read_pr_file", "write_release_notes"}}
+	default:
+		return Policy{Stage: stage}
+	}
+}
+
+func (p Policy) AllowsTool(name string) error {
+	for _, tool := range p.Tools {
+		if tool == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("tool %s is not allowed during %s", name, p.stageName())
+}
+
+// CheckCommand allows read-only `gh api` GETs against GitHub and local
+// `git show|diff|log`. argv is executed without a shell.
+func (p Policy) CheckCommand(argv []string) error {
+	if !p.Commands {
+		return fmt.Errorf("commands are not allowed during %s", p.stageName())
+	}
+	if len(argv) == 0 {
+		return errors.New("empty command")
+	}
+	switch argv[0] {
+	case "gh":
+		return checkGH(argv[1:])
+	case "git":
+		return checkGit(argv[1:])
+	default:
+		return fmt.Errorf("%s is not allowed (want gh api GET or git show|diff|log)", argv[0])
+	}
+}
+
+func checkGH(args []string) error {
+	if len(args) == 0 || args[0] != "api" {
+		return errors.New("only `gh api` is allowed")
+	}
+	endpoint := ""
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-X" || arg == "--method":
+			if i+1 >= len(args) || !strings.EqualFold(args[i+1], "GET") {
+				return errors.New("gh api: only GET is allowed")
+			}
+			i++
+		case strings.HasPrefix(arg, "-X") || strings.HasPrefix(arg, "--method="):
+			value := strings.TrimPrefix(strings.TrimPrefix(arg, "--method="), "-X")
+			if !strings.EqualFold(value, "GET") {
+				return errors.New("gh api: only GET is allowed")
+			}
+		case arg == "--paginate" || arg == "--jq" || arg == "-q" || arg == "--include" || arg == "-i" || arg == "--silent":
+			if arg == "--jq" || arg == "-q" {
+				i++
+			}
+		case strings.HasPrefix(arg, "-"):
+			// -f/-F/--input switch gh api to POST; --hostname leaves GitHub.
+			return fmt.Errorf("gh api: flag %s is not allowed", arg)
+		default:
+			if endpoint != "" {
+				return errors.New("gh api: only one endpoint is allowed")
+			}
+			endpoint = arg
+		}
+	}
+	if endpoint == "" {
+		return errors.New("gh api: endpoint is required")
+	}
+	if strings.Contains(endpoint, "://") || strings.HasPrefix(endpoint, "//") {
+		return errors.New("gh api: endpoint must be a GitHub API path, not a URL")
+	}
+	if endpoint == "graphql" {
+		return errors.New("gh api: graphql is not allowed")
+	}
+	return nil
+}
+
+func checkGit(args []string) error {
+	if len(args) == 0 {
+		return errors.New("git: subcommand is required")
+	}
+	switch args[0] {
+	case "show", "diff", "log":
+	default:
+		return fmt.Errorf("git %s is not allowed (want show|diff|log)", args[0])
+	}
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "--output") || arg == "--ext-diff" || strings.HasPrefix(arg, "--exec") {
+			return fmt.Errorf("git: flag %s is not allowed", arg)
+		}
+	}
+	return nil
+}
+
+func (p Policy) stageName() string {
+	if p.Stage == "" {
+		return "this stage"
+	}
+	return p.Stage
+}
+
+// Record appends one blocked attempt to the JSONL log at path.
+func Record(path string, stage string, attempt string, reason error) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.Marshal(Blocked{Time: time.Now().UTC(), Stage: stage, Attempt: attempt, Reason: reason.Error()})
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open blocked log: %w", err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+func ReadBlocked(path string) ([]Blocked, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	items := []Blocked{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item Blocked
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// Command builds an argv that already passed CheckCommand, without a shell.
+// gh runs in the data root with the GitHub token; git runs in repo/ and never
+// sees it.
+func Command(ctx context.Context, cfg config.Config, argv []string) (*exec.Cmd, error) {
+	bin, err := exec.LookPath(argv[0])
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, bin, argv[1:]...)
+	cmd.Dir = cfg.DataRoot
+	cmd.Env = gh.Env()
+	if argv[0] == "git" {
+		cmd.Dir = cfg.RepoDir
+		cmd.Env = gh.WithoutTokens(os.Environ())
+	}
+	return cmd, nil
+}
+
+// Exec runs Command and returns its combined output.
+func Exec(ctx context.Context, cfg config.Config, argv []string) ([]byte, error) {
+	cmd, err := Command(ctx, cfg, argv)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.CombinedOutput()
+}
+
+// StageEnv, BlockedLogEnv and PREnv are set by the runner for shell commands
+// it runs on a session's behalf (replay recordings), so `triage tool` applies
+// the session's stage policy, logs refusals for its input and stays on its PR,
+// whatever flags the command passes.
+const (
+	StageEnv      = "TRIAGE_STAGE"
+	BlockedLogEnv = "TRIAGE_BLOCKED_LOG"
+	PREnv         = "TRIAGE_PR"
+)
+
+// BlockedLog is the per-input log of refused attempts for one stage run.
+func BlockedLog(cfg config.Config, stage string, input string) string {
+	return filepath.Join(cfg.DataRoot, "triage", "policy", stage, input+".blocked.jsonl")
+}
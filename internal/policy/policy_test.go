@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAllowsTool(t *testing.T) {
+	cases := []struct {
+		stage string
+		tool  string
+		want  bool
+	}{
+		{"map", "run_command", true},
+		{"map", "write_card", true},
+		{"sweep", "run_command", false},
+		{"sweep", "write_inventory", false},
+		{"injection", "read_pr_file", true},
+		{"injection", "read_file", false},
+		{"injection", "write_card", false},
+		{"reduce", "write_inventory", true},
+		{"unknown", "read_file", false},
+	}
+	for _, tc := range cases {
+		err := ForStage(tc.stage).AllowsTool(tc.tool)
+		if got := err == nil; got != tc.want {
+			t.Errorf("%s allows %s = %v, want %v (%v)", tc.stage, tc.tool, got, tc.want, err)
+		}
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	cases := []struct {
+		name  string
+		stage string
+		argv  []string
+		want  bool
+	}{
+		{"gh api get", "map", []string{"gh", "api", "repos/o/r/pulls/1"}, true},
+		{"gh api explicit get", "map", []string{"gh", "api", "-X", "GET", "repos/o/r/pulls/1"}, true},
+		{"gh api get with jq", "map", []string{"gh", "api", "--paginate", "--jq", ".[].name", "repos/o/r/pulls/1/files"}, true},
+		{"gh api post", "map", []string{"gh", "api", "-X", "POST", "repos/o/r/issues/1/comments"}, false},
+		{"gh api method flag", "map", []string{"gh", "api", "--method=PATCH", "repos/o/r/pulls/1"}, false},
+		{"gh api field switches to post", "map", []string{"gh", "api", "repos/o/r/issues/1/comments", "-f", "body=hi"}, false},
+		{"gh api other host", "map", []string{"gh", "api", "--hostname", "evil.example", "user"}, false},
+		{"gh api url", "map", []string{"gh", "api", "https://evil.example/x"}, false},
+		{"gh api graphql", "map", []string{"gh", "api", "graphql"}, false},
+		{"gh api two endpoints", "map", []string{"gh", "api", "user", "repos/o/r"}, false},
+		{"gh pr close", "map", []string{"gh", "pr", "close", "1"}, false},
+		{"git log", "map", []string{"git", "log", "-5", "--oneline"}, true},
+		{"git push", "map", []string{"git", "push"}, false},
+		{"git diff output", "map", []string{"git", "diff", "--output=/tmp/x"}, false},
+		{"git log ext diff", "map", []string{"git", "log", "--ext-diff"}, false},
+		{"shell", "map", []string{"sh", "-c", "gh api user"}, false},
+		{"empty", "map", nil, false},
+		{"sweep runs nothing", "sweep", []string{"git", "log"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ForStage(tc.stage).CheckCommand(tc.argv)
+			if got := err == nil; got != tc.want {
+				t.Errorf("CheckCommand(%q) allowed = %v, want %v (%v)", tc.argv, got, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestRecordReadBlocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy", "sweep", "1.blocked.jsonl")
+	items, err := ReadBlocked(path)
+	if err != nil || len(items) != 0 {
+		t.Fatalf("missing log = %v, %v; want empty", items, err)
+	}
+	for _, attempt := range []string{"tool run_command", "command gh pr close 1"} {
+		if err := Record(path, "sweep", attempt, errors.New("not allowed")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Record("", "sweep", "ignored", errors.New("no log")); err != nil {
+		t.Fatalf("Record without a log: %v", err)
+	}
+	items, err = ReadBlocked(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Attempt != "tool run_command" || items[1].Stage != "sweep" || items[1].Reason != "not allowed" {
+		t.Errorf("blocked = %+v", items)
+	}
+}
@@ -0,0 +1,259 @@
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// DefaultComment is the close comment template used when
+// triage/close/comment.md does not exist.
+const DefaultComment = `Thanks for the PR, @{{.Author}}. We're closing it during triage of {{.Repo}}: {{.Summary}}
+
+If we got this wrong, reply here and a maintainer will take another look.
+`
+
+// CommentData is what the close comment template is rendered with.
+type CommentData struct {
+	Repo    string
+	PR      int
+	Author  string
+	Summary string
+	// Label is the sweep card label's display name, empty if the card is gone.
+	Label string
+}
+
+// Planned is one PR of the close plan. Skip says why it will not be closed.
+type Planned struct {
+	CommentData
+	Comment string
+	Skip    string
+}
+
+// Plan is what `triage close-apply` would do with the queue at Path. Hash
+// covers the PRs and their rendered comments.
+type Plan struct {
+	Path  string
+	Hash  string
+	PRs   []Planned
+	Close int
+}
+
+// Applied is one line of triage/close/applied.jsonl.
+type Applied struct {
+	Time time.Time `json:"time"`
+	PR   int       `json:"pr"`
+	// Queue is the plan hash the close was approved under.
+	Queue string `json:"queue"`
+}
+
+var queueLineRe = regexp.MustCompile(`^- #(\d+) — (.*) \(author: ([^)]*)\)$`)
+
+// CommentPath is the close comment template override under the data root.
+func CommentPath(cfg config.Config) string {
+	return filepath.Join(cfg.TriageDir, "close", "comment.md")
+}
+
+// AppliedPath logs the PRs close-apply has closed.
+func AppliedPath(cfg config.Config) string {
+	return filepath.Join(cfg.TriageDir, "close", "applied.jsonl")
+}
+
+// BuildPlan reads the queue at path (as written by WriteCloseQueue) and
+// renders the close comment for each PR. A top-level line that is not a PR
+// entry is an error, so an edit that breaks an entry cannot drop its PR. PRs
+// state.json shows as no longer open, or that applied.jsonl records as closed
+// from this same plan, are skipped.
+func BuildPlan(cfg config.Config, tax taxonomy.Taxonomy, path string, comment []byte) (Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Plan{}, fmt.Errorf("read close queue (run `triage close-queue`): %w", err)
+	}
+	tmpl, err := template.New("comment").Parse(string(comment))
+	if err != nil {
+		return Plan{}, fmt.Errorf("parse close comment: %w", err)
+	}
+	plan := Plan{Path: path}
+
+	labels := map[int]string{}
+	if _, err := os.Stat(cfg.SweepDir); err == nil {
+		records, err := card.ReadDir(cfg.SweepDir)
+		if err != nil {
+			return Plan{}, err
+		}
+		for _, rec := range records {
+			labels[rec.PR] = tax.Display(rec.Label)
+		}
+	}
+
+	seen := map[int]bool{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if skipQueueLine(line) {
+			continue
+		}
+		m := queueLineRe.FindStringSubmatch(line)
+		if m == nil {
+			return Plan{}, fmt.Errorf("%s line %d is not a queue entry (want \"- #N — summary (author: login)\"): %q", path, i+1, line)
+		}
+		number, _ := strconv.Atoi(m[1])
+		if seen[number] {
+			return Plan{}, fmt.Errorf("%s line %d: #%d is listed twice", path, i+1, number)
+		}
+		seen[number] = true
+		p := Planned{CommentData: CommentData{Repo: cfg.Repo, PR: number, Summary: m[2], Author: m[3], Label: labels[number]}}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, p.CommentData); err != nil {
+			return Plan{}, fmt.Errorf("render close comment for #%d: %w", number, err)
+		}
+		p.Comment = strings.TrimSpace(b.String())
+		plan.PRs = append(plan.PRs, p)
+	}
+	if len(plan.PRs) == 0 {
+		return Plan{}, fmt.Errorf("no PRs in %s", path)
+	}
+	plan.Hash = planHash(plan.PRs)
+
+	done, err := readApplied(AppliedPath(cfg), plan.Hash)
+	if err != nil {
+		return Plan{}, err
+	}
+	state, err := ingest.LoadState(cfg.StatePath)
+	if err != nil {
+		return Plan{}, err
+	}
+	for i := range plan.PRs {
+		p := &plan.PRs[i]
+		switch now := state.StateOf(p.PR, rawState(cfg, p.PR)); {
+		case done[p.PR]:
+			p.Skip = "already closed by close-apply"
+		case now != "" && now != "open":
+			p.Skip = "state.json says " + now
+		default:
+			plan.Close++
+		}
+	}
+	return plan, nil
+}
+
+// skipQueueLine reports whether line is part of the queue but not an entry:
+// blank, a heading, the close-ready count, or an entry's indented details.
+func skipQueueLine(line string) bool {
+	return strings.TrimSpace(line) == "" ||
+		strings.HasPrefix(line, "#") ||
+		strings.HasPrefix(line, "- close-ready:") ||
+		strings.HasPrefix(line, " ")
+}
+
+// planHash is what --approve must name: every PR of the plan with the
+// comment it will be closed with. Editing the queue, comment.md or
+// --comment-file changes it; PRs being skipped do not, so a run that stopped
+// part way resumes under the same hash.
+func planHash(prs []Planned) string {
+	var b strings.Builder
+	for _, p := range prs {
+		b.WriteString(fmt.Sprintf("#%d\n%s\n\n", p.PR, p.Comment))
+	}
+	return rubric.ShortHash([]byte(b.String()))
+}
+
+// RenderPlan writes the dry-run plan: every PR with its comment, and how to
+// approve it.
+func RenderPlan(plan Plan) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Close plan — %s\n", plan.Path))
+	b.WriteString(fmt.Sprintf("Plan: %s · %d to close, %d skipped\n\n", plan.Hash, plan.Close, len(plan.PRs)-plan.Close))
+	for _, p := range plan.PRs {
+		if p.Skip != "" {
+			b.WriteString(fmt.Sprintf("- #%d skip (%s)\n", p.PR, p.Skip))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("- #%d close, comment:\n", p.PR))
+		for _, line := range strings.Split(p.Comment, "\n") {
+			b.WriteString(strings.TrimRight("  > "+line, " ") + "\n")
+		}
+	}
+	if plan.Close > 0 {
+		b.WriteString(fmt.Sprintf("\nDry run: nothing was closed. To close, re-run with --approve %s\n", plan.Hash))
+	}
+	return b.String()
+}
+
+// RecordApplied appends a closed PR to applied.jsonl.
+func RecordApplied(cfg config.Config, entry Applied) error {
+	path := AppliedPath(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open close log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write close log: %w", err)
+	}
+	return nil
+}
+
+func readApplied(path, hash string) (map[int]bool, error) {
+	done := map[int]bool{}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open close log: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		entry := Applied{}
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			return nil, fmt.Errorf("parse close log line %d: %w", line, err)
+		}
+		if entry.Queue == hash {
+			done[entry.PR] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read close log: %w", err)
+	}
+	return done, nil
+}
+
+// rawState is the cached PR state, empty when the PR is not cached. It is
+// only the fallback for PRs state.json does not know.
+func rawState(cfg config.Config, number int) string {
+	var raw struct {
+		State string `json:"state"`
+	}
+	if err := storage.ReadJSON(cfg.RawPRPath(number), &raw); err != nil {
+		return ""
+	}
+	return raw.State
+}
@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/evidence"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+const testQueue = `# Close Queue — 2026-01-01 00:00:00 UTC
+
+- close-ready: 2
+
+- #1 — Adds a widget (author: bob)
+  - note: close-ready: yes spam
+
+- #2 — Empty PR (author: eve)
+  - evidence: "nothing" (pr)
+`
+
+// setup writes queue to a data root and returns its config and queue path.
+func setup(t *testing.T, queue string) (config.Config, string) {
+	t.Helper()
+	cfg := config.FromDataRoot(filepath.Join(t.TempDir(), "o", "r"))
+	path := filepath.Join(cfg.TriageDir, "close", "queue.md")
+	if err := storage.WriteFileAtomic(path, []byte(queue), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return cfg, path
+}
+
+func TestBuildPlan(t *testing.T) {
+	cases := []struct {
+		name    string
+		queue   string
+		state   string // state.json, if any
+		applied []int  // PRs applied.jsonl records under the plan's hash
+		wantErr string
+		skip    map[int]string
+		close   int
+	}{
+		{name: "every entry", queue: testQueue, close: 2},
+		{name: "broken entry", queue: testQueue + "- #3 — no author here\n", wantErr: "line 10 is not a queue entry"},
+		{name: "stray text", queue: testQueue + "closing these on friday\n", wantErr: "is not a queue entry"},
+		{name: "listed twice", queue: testQueue + "- #1 — Again (author: bob)\n", wantErr: "#1 is listed twice"},
+		{name: "empty queue", queue: "# Close Queue\n\n- close-ready: 0\n", wantErr: "no PRs"},
+		{name: "closed since", queue: testQueue, state: `{"prs":{"2":{"state":"closed"}}}`, skip: map[int]string{2: "state.json says closed"}, close: 1},
+		{name: "open in state.json", queue: testQueue, state: `{"prs":{"1":{"state":"open"}}}`, close: 2},
+		{name: "already applied", queue: testQueue, applied: []int{1}, skip: map[int]string{1: "already closed by close-apply"}, close: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, path := setup(t, tc.queue)
+			if tc.state != "" {
+				if err := storage.WriteFileAtomic(cfg.StatePath, []byte(tc.state), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(tc.applied) > 0 {
+				plan, err := BuildPlan(cfg, taxonomy.Default(), path, []byte(DefaultComment))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, pr := range tc.applied {
+					if err := RecordApplied(cfg, Applied{Time: time.Now(), PR: pr, Queue: plan.Hash}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			plan, err := BuildPlan(cfg, taxonomy.Default(), path, []byte(DefaultComment))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("BuildPlan error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Close != tc.close {
+				t.Errorf("close = %d, want %d", plan.Close, tc.close)
+			}
+			for _, p := range plan.PRs {
+				if p.Skip != tc.skip[p.PR] {
+					t.Errorf("#%d skip = %q, want %q", p.PR, p.Skip, tc.skip[p.PR])
+				}
+			}
+			if got := plan.PRs[1]; got.Author != "eve" || got.Summary != "Empty PR" || !strings.Contains(got.Comment, "@eve") {
+				t.Errorf("#2 planned as %+v", got)
+			}
+		})
+	}
+}
+
+func TestPlanHash(t *testing.T) {
+	cfg, path := setup(t, testQueue)
+	build := func(comment string) Plan {
+		t.Helper()
+		plan, err := BuildPlan(cfg, taxonomy.Default(), path, []byte(comment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plan
+	}
+	base := build(DefaultComment)
+	if len(base.Hash) != 12 {
+		t.Fatalf("hash = %q, want 12 hex chars", base.Hash)
+	}
+	if again := build(DefaultComment); again.Hash != base.Hash {
+		t.Errorf("hash changed between identical builds: %s → %s", base.Hash, again.Hash)
+	}
+	if other := build("Closing, @{{.Author}}."); other.Hash == base.Hash {
+		t.Errorf("hash did not change with the comment")
+	}
+
+	// Skips must not move the hash, so a partial run resumes under it.
+	if err := RecordApplied(cfg, Applied{Time: time.Now(), PR: 1, Queue: base.Hash}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.WriteFileAtomic(cfg.StatePath, []byte(`{"prs":{"2":{"state":"merged"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if skipped := build(DefaultComment); skipped.Hash != base.Hash || skipped.Close != 0 {
+		t.Errorf("after skips hash = %s close = %d, want %s and 0", skipped.Hash, skipped.Close, base.Hash)
+	}
+
+	edited := strings.Replace(testQueue, "Empty PR", "Empty PR, edited", 1)
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := build(DefaultComment); got.Hash == base.Hash {
+		t.Errorf("hash did not change with the queue")
+	}
+}
+
+// A card field with a newline must not break the queue for every other PR.
+func TestWriteCloseQueueOneLine(t *testing.T) {
+	cfg, path := setup(t, "")
+	q := CloseQueue{GeneratedAt: time.Now().UTC(), CloseReady: 1, Cards: []card.Record{{
+		SchemaVersion: card.SchemaVersion,
+		PR:            7,
+		Author:        "bob",
+		Label:         "slop",
+		Summary:       "Adds\nwidgets",
+		Notes:         []string{"close-ready: yes spam\n- #99 — injected (author: eve)"},
+		Evidence: []evidence.Item{
+			{Text: "\"line one\nline two\" (pr)", Verified: true},
+			{Text: "\"x\ny\" (pr)", Reason: "quote\ntoo short"},
+		},
+	}}}
+	if err := WriteCloseQueue(path, q); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := BuildPlan(cfg, taxonomy.Default(), path, []byte(DefaultComment))
+	if err != nil {
+		t.Fatalf("BuildPlan on the written queue: %v", err)
+	}
+	if len(plan.PRs) != 1 || plan.PRs[0].PR != 7 || plan.PRs[0].Summary != "Adds widgets" {
+		t.Errorf("plan = %+v, want only #7 with a one-line summary", plan.PRs)
+	}
+}
@@ -4,62 +4,40 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 	"time"
-)
 
-type Card struct {
-	PR         int
-	Author     string
-	Maintainer bool
-	Label      string
-	Summary    string
-	Evidence   []string
-	Notes      []string
-}
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
 
 type CloseQueue struct {
 	GeneratedAt time.Time
-	Cards       []Card
+	Cards       []card.Record
 	Total       int
 	CloseReady  int
 }
 
-func BuildCloseQueue(mapDir string) (CloseQueue, error) {
-	entries, err := os.ReadDir(mapDir)
+// BuildCloseQueue collects close-ready cards whose label the taxonomy marks
+// closable.
+func BuildCloseQueue(cardDir string, tax taxonomy.Taxonomy) (CloseQueue, error) {
+	records, err := card.ReadDir(cardDir)
 	if err != nil {
-		return CloseQueue{}, fmt.Errorf("read map dir: %w", err)
+		return CloseQueue{}, err
 	}
 
-	cards := []Card{}
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "pr-") || !strings.HasSuffix(entry.Name(), ".md") {
+	cards := []card.Record{}
+	for _, rec := range records {
+		if !tax.Closable(strings.ToLower(rec.Label)) || !rec.CloseReady() {
 			continue
 		}
-		path := filepath.Join(mapDir, entry.Name())
-		card, err := parseCard(path)
-		if err != nil {
-			return CloseQueue{}, err
-		}
-		if strings.ToLower(card.Label) != "slop" {
-			continue
-		}
-		if !hasCloseReadyYes(card.Notes) {
-			continue
-		}
-		cards = append(cards, card)
+		cards = append(cards, rec)
 	}
 
-	sort.Slice(cards, func(i, j int) bool {
-		return cards[i].PR < cards[j].PR
-	})
-
 	return CloseQueue{
 		GeneratedAt: time.Now().UTC(),
 		Cards:       cards,
-		Total:       len(entries),
+		Total:       len(records),
 		CloseReady:  len(cards),
 	}, nil
 }
@@ -73,83 +51,29 @@ func WriteCloseQueue(path string, queue CloseQueue) error {
 	b.WriteString(fmt.Sprintf("# Close Queue — %s\n\n", queue.GeneratedAt.Format("2006-01-02 15:04:05 MST")))
 	b.WriteString(fmt.Sprintf("- close-ready: %d\n\n", queue.CloseReady))
 
-	for _, card := range queue.Cards {
-		b.WriteString(fmt.Sprintf("- #%d — %s (author: %s)\n", card.PR, card.Summary, card.Author))
-		for _, note := range card.Notes {
-			b.WriteString(fmt.Sprintf("  - note: %s\n", note))
+	for _, rec := range queue.Cards {
+		// One line per entry and sub-item: close-apply rejects anything
+		// else, so every model-written field goes through oneLine.
+		b.WriteString(fmt.Sprintf("- #%d — %s (author: %s)\n", rec.PR, oneLine(rec.Summary), oneLine(rec.Author)))
+		if rec.Injection {
+			b.WriteString("  - injection: suspected\n")
 		}
-		for _, ev := range card.Evidence {
-			b.WriteString(fmt.Sprintf("  - evidence: %s\n", ev))
+		for _, note := range rec.Notes {
+			b.WriteString(fmt.Sprintf("  - note: %s\n", oneLine(note)))
 		}
-		b.WriteString("\n")
-	}
-
-	return os.WriteFile(path, []byte(b.String()), 0o644)
-}
-
-func parseCard(path string) (Card, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return Card{}, fmt.Errorf("read card %s: %w", path, err)
-	}
-
-	lines := strings.Split(string(data), "\n")
-	card := Card{}
-	section := ""
-
-	for _, raw := range lines {
-		line := strings.TrimSpace(raw)
-		switch {
-		case strings.HasPrefix(line, "PR: #"):
-			value := strings.TrimPrefix(line, "PR: #")
-			if num, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
-				card.PR = num
-			}
-		case strings.HasPrefix(line, "Author:"):
-			card.Author = strings.TrimSpace(strings.TrimPrefix(line, "Author:"))
-		case strings.HasPrefix(line, "Maintainer:"):
-			card.Maintainer = strings.TrimSpace(strings.TrimPrefix(line, "Maintainer:")) == "yes"
-		case strings.HasPrefix(line, "Label:"):
-			card.Label = strings.TrimSpace(strings.TrimPrefix(line, "Label:"))
-		case line == "## Summary":
-			section = "summary"
-		case line == "## Evidence":
-			section = "evidence"
-		case line == "## Notes":
-			section = "notes"
-		case strings.HasPrefix(line, "## "):
-			section = ""
-		default:
-			switch section {
-			case "summary":
-				if strings.HasPrefix(line, "-") {
-					card.Summary = strings.TrimSpace(strings.TrimPrefix(line, "-"))
-				}
-			case "evidence":
-				if strings.HasPrefix(line, "-") {
-					card.Evidence = append(card.Evidence, strings.TrimSpace(strings.TrimPrefix(line, "-")))
-				}
-			case "notes":
-				if strings.HasPrefix(line, "-") {
-					card.Notes = append(card.Notes, strings.TrimSpace(strings.TrimPrefix(line, "-")))
-				}
+		for _, ev := range rec.Evidence {
+			if rec.SchemaVersion >= 2 && !ev.Verified {
+				b.WriteString(fmt.Sprintf("  - evidence (unverified): %s\n", oneLine(ev.Text)))
+				continue
 			}
+			b.WriteString(fmt.Sprintf("  - evidence: %s\n", oneLine(ev.Text)))
 		}
+		b.WriteString("\n")
 	}
 
-	if card.PR == 0 {
-		return Card{}, fmt.Errorf("missing PR number in %s", path)
-	}
-
-	return card, nil
+	return os.WriteFile(path, []byte(b.String()), 0o644)
 }
 
-func hasCloseReadyYes(notes []string) bool {
-	for _, note := range notes {
-		value := strings.ToLower(strings.TrimSpace(note))
-		if strings.HasPrefix(value, "close-ready: yes") {
-			return true
-		}
-	}
-	return false
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
 }
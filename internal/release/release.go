@@ -0,0 +1,321 @@
+// Package release drafts release notes from merged PRs. The CLI picks the
+// candidates (merged since a date, carded with one of the given labels) and
+// writes them to triage/release-notes/input.json; the model groups and words
+// them and calls write_release_notes, which the CLI validates against the
+// input and renders as triage/release-notes/draft.md.
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Dir holds the release notes files, relative to the data root.
+const Dir = "triage/release-notes"
+
+// DefaultLabels are the card labels whose merged PRs are candidates.
+var DefaultLabels = []string{"good", "needs-human"}
+
+// Candidate is one merged PR the notes may mention.
+type Candidate struct {
+	PR       int      `json:"pr"`
+	Title    string   `json:"title"`
+	Author   string   `json:"author"`
+	URL      string   `json:"url"`
+	MergedAt string   `json:"merged_at"`
+	Label    string   `json:"label"`
+	Summary  string   `json:"summary"`
+	Evidence []string `json:"evidence,omitempty"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// Input is triage/release-notes/input.json.
+type Input struct {
+	Repo   string      `json:"repo"`
+	Since  string      `json:"since"` // as given: a tag or a date
+	From   time.Time   `json:"from"`
+	Labels []string    `json:"labels"`
+	PRs    []Candidate `json:"prs"`
+	// Skipped counts merged PRs whose snapshot has no mergedAt (cached
+	// before ingest fetched it).
+	Skipped int `json:"skipped,omitempty"`
+}
+
+// Entry is one changelog line covering one or more PRs.
+type Entry struct {
+	PRs  []int  `json:"prs"`
+	Text string `json:"text"`
+}
+
+// Section is one changelog category.
+type Section struct {
+	Title   string  `json:"title"`
+	Entries []Entry `json:"entries"`
+}
+
+// Notes is what the model (or a human through write-release-notes) writes.
+type Notes struct {
+	Intro    string    `json:"intro,omitempty"`
+	Sections []Section `json:"sections"`
+}
+
+type rawPR struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	State    string `json:"state"`
+	MergedAt string `json:"mergedAt"`
+	Author   struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// InputPath and DraftPath are the input and rendered draft under root.
+func InputPath(root string) string {
+	return filepath.Join(root, filepath.FromSlash(Dir), "input.json")
+}
+
+func DraftPath(root string) string {
+	return filepath.Join(root, filepath.FromSlash(Dir), "draft.md")
+}
+
+// Prepare collects the merged PRs since from with a map card label in
+// labels and writes input.json.
+func Prepare(cfg config.Config, tax taxonomy.Taxonomy, since string, from time.Time, labels []string) (Input, error) {
+	if len(labels) == 0 {
+		return Input{}, errors.New("no labels given")
+	}
+	for _, label := range labels {
+		if err := tax.Validate(label, ""); err != nil {
+			return Input{}, err
+		}
+	}
+	in := Input{Repo: cfg.Repo, Since: since, From: from.UTC(), Labels: labels, PRs: []Candidate{}}
+	records := []card.Record{}
+	if _, err := os.Stat(cfg.MapDir); err == nil {
+		var err error
+		records, err = card.ReadDir(cfg.MapDir)
+		if err != nil {
+			return Input{}, err
+		}
+	}
+	cards := map[int]card.Record{}
+	for _, rec := range records {
+		cards[rec.PR] = rec
+	}
+
+	cached, err := ingest.CachedPRs(cfg)
+	if err != nil {
+		return Input{}, err
+	}
+	for _, pr := range cached {
+		number := pr.Number
+		raw := rawPR{}
+		if err := json.Unmarshal(pr.Raw, &raw); err != nil {
+			return Input{}, fmt.Errorf("parse pr-%d: %w", number, err)
+		}
+		// Merged is final, so the snapshot is trusted here: an open-list
+		// ingest records merged PRs that left the list as closed.
+		if !strings.EqualFold(raw.State, "merged") {
+			continue
+		}
+		rec, ok := cards[number]
+		if !ok || rec.Maintainer || !contains(labels, rec.Label) {
+			continue
+		}
+		merged, err := time.Parse(time.RFC3339, raw.MergedAt)
+		if err != nil {
+			in.Skipped++
+			continue
+		}
+		if merged.Before(from) {
+			continue
+		}
+		c := Candidate{PR: number, Title: raw.Title, Author: raw.Author.Login, URL: raw.URL, MergedAt: raw.MergedAt, Label: rec.Label, Summary: rec.Summary}
+		if c.URL == "" {
+			c.URL = fmt.Sprintf("https://github.com/%s/pull/%d", cfg.Repo, number)
+		}
+		for _, ev := range rec.Evidence {
+			c.Evidence = append(c.Evidence, ev.Text)
+		}
+		var files struct {
+			Files []string `json:"files"`
+		}
+		if err := storage.ReadJSON(cfg.RawPRFilesPath(number), &files); err == nil {
+			c.Files = files.Files
+		}
+		in.PRs = append(in.PRs, c)
+	}
+	sort.Slice(in.PRs, func(i, j int) bool { return in.PRs[i].PR < in.PRs[j].PR })
+	if err := storage.WriteJSONAtomic(InputPath(cfg.DataRoot), in); err != nil {
+		return Input{}, err
+	}
+	return in, nil
+}
+
+// LoadInput reads input.json under root.
+func LoadInput(root string) (Input, []byte, error) {
+	data, err := os.ReadFile(InputPath(root))
+	if err != nil {
+		return Input{}, nil, fmt.Errorf("no release notes input (run `triage release-notes`): %w", err)
+	}
+	in := Input{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return Input{}, nil, fmt.Errorf("parse %s: %w", InputPath(root), err)
+	}
+	return in, data, nil
+}
+
+// Validate checks notes against the input: every entry cites input PRs, no
+// PR is cited twice.
+func Validate(in Input, notes Notes) error {
+	known := map[int]bool{}
+	for _, c := range in.PRs {
+		known[c.PR] = true
+	}
+	if len(notes.Sections) == 0 {
+		return errors.New("sections are required")
+	}
+	cited := map[int]bool{}
+	for i, section := range notes.Sections {
+		if strings.TrimSpace(section.Title) == "" {
+			return fmt.Errorf("section %d: title is required", i+1)
+		}
+		if len(section.Entries) == 0 {
+			return fmt.Errorf("section %q: entries are required", section.Title)
+		}
+		for j, entry := range section.Entries {
+			if strings.TrimSpace(entry.Text) == "" {
+				return fmt.Errorf("section %q entry %d: text is required", section.Title, j+1)
+			}
+			if len(entry.PRs) == 0 {
+				return fmt.Errorf("section %q entry %d: prs are required", section.Title, j+1)
+			}
+			for _, pr := range entry.PRs {
+				if !known[pr] {
+					return fmt.Errorf("section %q entry %d: #%d is not in %s", section.Title, j+1, pr, filepath.ToSlash(filepath.Join(Dir, "input.json")))
+				}
+				if cited[pr] {
+					return fmt.Errorf("section %q entry %d: #%d is already cited", section.Title, j+1, pr)
+				}
+				cited[pr] = true
+			}
+		}
+	}
+	return nil
+}
+
+// Write validates notes against input.json under root and writes draft.md
+// (stamped with the input's hash) and draft.json.
+func Write(root string, notes Notes) (string, error) {
+	in, data, err := LoadInput(root)
+	if err != nil {
+		return "", err
+	}
+	if err := Validate(in, notes); err != nil {
+		return "", err
+	}
+	path := DraftPath(root)
+	if err := storage.WriteFileAtomic(path, []byte(Render(in, rubric.ShortHash(data), notes)), 0o644); err != nil {
+		return "", err
+	}
+	if err := storage.WriteJSONAtomic(strings.TrimSuffix(path, ".md")+".json", notes); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// DraftCurrent reports whether draft.md was written from the current
+// input.json.
+func DraftCurrent(root string) (bool, error) {
+	draft, err := os.ReadFile(DraftPath(root))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read draft: %w", err)
+	}
+	_, data, err := LoadInput(root)
+	if err != nil {
+		return false, err
+	}
+	m := inputHashRe.FindSubmatch(draft)
+	return m != nil && string(m[1]) == rubric.ShortHash(data), nil
+}
+
+var inputHashRe = regexp.MustCompile(`(?m)^Input: (\S+)`)
+
+// Render writes the draft: the sections in the model's order, each entry
+// linked to its PRs and authors, then the candidates the notes left out so
+// a human can check them.
+func Render(in Input, hash string, notes Notes) string {
+	byPR := map[int]Candidate{}
+	for _, c := range in.PRs {
+		byPR[c.PR] = c
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Release notes draft — %s (since %s)\n", in.Repo, in.Since))
+	b.WriteString(fmt.Sprintf("Input: %s · %d merged PRs (%s) since %s\n\n", hash, len(in.PRs), strings.Join(in.Labels, ", "), in.From.Format("2006-01-02 15:04")))
+	if intro := strings.TrimSpace(notes.Intro); intro != "" {
+		b.WriteString(intro + "\n\n")
+	}
+	cited := map[int]bool{}
+	for _, section := range notes.Sections {
+		b.WriteString(fmt.Sprintf("## %s\n", strings.TrimSpace(section.Title)))
+		for _, entry := range section.Entries {
+			refs := []string{}
+			authors := []string{}
+			seen := map[string]bool{}
+			for _, pr := range entry.PRs {
+				cited[pr] = true
+				refs = append(refs, fmt.Sprintf("[#%d](%s)", pr, byPR[pr].URL))
+				if author := byPR[pr].Author; author != "" && !seen[author] {
+					seen[author] = true
+					authors = append(authors, "@"+author)
+				}
+			}
+			line := fmt.Sprintf("- %s (%s", strings.TrimSpace(entry.Text), strings.Join(refs, ", "))
+			if len(authors) > 0 {
+				line += ", " + strings.Join(authors, ", ")
+			}
+			b.WriteString(line + ")\n")
+		}
+		b.WriteString("\n")
+	}
+	left := []Candidate{}
+	for _, c := range in.PRs {
+		if !cited[c.PR] {
+			left = append(left, c)
+		}
+	}
+	if len(left) > 0 {
+		b.WriteString(fmt.Sprintf("## Not in the notes (%d)\n", len(left)))
+		for _, c := range left {
+			b.WriteString(fmt.Sprintf("- #%d %s (%s) — %s\n", c.PR, c.Title, c.Label, c.Summary))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Markdown renders the report for a terminal or a chat paste.
+func Markdown(rep Report) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Triage report — %s (%s)\n", rep.Repo, rep.Window()))
+	b.WriteString(fmt.Sprintf("%s → %s UTC\n\n", rep.Since.UTC().Format("2006-01-02 15:04"), rep.Until.UTC().Format("2006-01-02 15:04")))
+
+	b.WriteString("## At a glance\n")
+	for _, line := range glance(rep) {
+		b.WriteString("- " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	if len(rep.NeedsHuman) > 0 {
+		b.WriteString("## Needs a human\n")
+		for _, group := range rep.NeedsHuman {
+			writeList(&b, fmt.Sprintf("### %s (%d open)", group.Title, group.Total), group.PRs, group.Total, prLine)
+		}
+	}
+	if len(rep.Carded) > 0 {
+		b.WriteString("## Newly carded\n")
+		for _, group := range rep.Carded {
+			writeList(&b, fmt.Sprintf("### %s (%d)", group.Title, group.Total), group.PRs, group.Total, prLine)
+		}
+	}
+	if len(rep.Relabeled) > 0 {
+		writeList(&b, fmt.Sprintf("## Label changes since %s", rep.Baseline), rep.Relabeled, len(rep.Relabeled), func(p PR) string {
+			return fmt.Sprintf("%s: %s → %s", prRef(p), p.From, p.Label)
+		})
+	}
+	if len(rep.Opened) > 0 {
+		writeList(&b, "## Opened", rep.Opened, len(rep.Opened), prLine)
+	}
+	if len(rep.Closed) > 0 {
+		writeList(&b, "## Closed / merged", rep.Closed, len(rep.Closed), func(p PR) string {
+			return fmt.Sprintf("%s (%s)", prRef(p), p.State)
+		})
+	}
+	if len(rep.Reopened) > 0 {
+		writeList(&b, "## Reopened", rep.Reopened, len(rep.Reopened), prLine)
+	}
+	return b.String()
+}
+
+func writeList(b *strings.Builder, heading string, prs []PR, total int, format func(PR) string) {
+	b.WriteString(heading + "\n")
+	for i, p := range prs {
+		if i == MaxItems {
+			break
+		}
+		b.WriteString("- " + format(p) + "\n")
+	}
+	if more := total - min(len(prs), MaxItems); more > 0 {
+		b.WriteString(fmt.Sprintf("- … and %d more\n", more))
+	}
+	b.WriteString("\n")
+}
+
+func glance(rep Report) []string {
+	lines := []string{}
+	if rep.NoEvents {
+		lines = append(lines, "ingest: no events logged yet (run `triage run`)")
+	} else {
+		lines = append(lines, fmt.Sprintf("ingest: %d opened, %d closed/merged, %d reopened, %d updated", len(rep.Opened), len(rep.Closed), len(rep.Reopened), rep.Updated))
+	}
+	carded := 0
+	for _, group := range rep.Carded {
+		carded += group.Total
+	}
+	parts := []string{}
+	for _, group := range rep.Carded {
+		parts = append(parts, fmt.Sprintf("%d %s", group.Total, group.Title))
+	}
+	line := fmt.Sprintf("carded: %d", carded)
+	if len(parts) > 0 {
+		line += " (" + strings.Join(parts, ", ") + ")"
+	}
+	if rep.Maintainer > 0 {
+		line += fmt.Sprintf(", %d maintainer skipped", rep.Maintainer)
+	}
+	lines = append(lines, line)
+	waiting := 0
+	for _, group := range rep.NeedsHuman {
+		waiting += group.Total
+	}
+	lines = append(lines, fmt.Sprintf("needs a human: %d open", waiting))
+	if rep.Baseline == "" {
+		lines = append(lines, "label changes: no snapshot from before the window")
+	} else {
+		lines = append(lines, fmt.Sprintf("label changes: %d since %s", len(rep.Relabeled), rep.Baseline))
+	}
+	lines = append(lines, fmt.Sprintf("close queue: %d close-ready of %d sweep cards", rep.CloseReady, rep.SweepTotal))
+	return lines
+}
+
+func prRef(p PR) string {
+	ref := fmt.Sprintf("#%d", p.Number)
+	if p.Title != "" {
+		ref += " " + p.Title
+	}
+	return ref
+}
+
+func prLine(p PR) string {
+	line := prRef(p)
+	if p.Author != "" {
+		line += " (@" + p.Author + ")"
+	}
+	if p.Summary != "" {
+		line += " — " + p.Summary
+	}
+	return line
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cap": func(prs []PR) []PR {
+		if len(prs) > MaxItems {
+			return prs[:MaxItems]
+		}
+		return prs
+	},
+	"more": func(prs []PR, total int) int {
+		return total - min(len(prs), MaxItems)
+	},
+}).Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Triage report — {{.Rep.Repo}} ({{.Rep.Window}})</title>
+<style>
+body { font: 15px/1.45 system-ui, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+h1 { font-size: 1.4rem; margin-bottom: .2rem; }
+h2 { font-size: 1.1rem; border-bottom: 1px solid #d0d7de; padding-bottom: .2rem; margin-top: 1.6rem; }
+h3 { font-size: 1rem; margin-bottom: .3rem; }
+.muted { color: #59636e; }
+ul { padding-left: 1.2rem; }
+li { margin: .15rem 0; }
+a { color: #0969da; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>Triage report — {{.Rep.Repo}}</h1>
+<p class="muted">{{.Rep.Window}}: {{.Rep.Since.UTC.Format "2006-01-02 15:04"}} → {{.Rep.Until.UTC.Format "2006-01-02 15:04"}} UTC</p>
+<h2>At a glance</h2>
+<ul>{{range .Glance}}<li>{{.}}</li>{{end}}</ul>
+{{define "pr"}}<a href="{{if .URL}}{{.URL}}{{else}}#{{end}}">#{{.Number}}</a>{{if .Title}} {{.Title}}{{end}}{{end}}
+{{define "list"}}{{range cap .PRs}}<li>{{template "pr" .}}{{if .Author}} <span class="muted">@{{.Author}}</span>{{end}}{{if .Summary}} — {{.Summary}}{{end}}</li>{{end}}{{with more .PRs .Total}}<li class="muted">… and {{.}} more</li>{{end}}{{end}}
+{{with .Rep.NeedsHuman}}<h2>Needs a human</h2>{{range .}}<h3>{{.Title}} ({{.Total}} open)</h3><ul>{{template "list" .}}</ul>{{end}}{{end}}
+{{with .Rep.Carded}}<h2>Newly carded</h2>{{range .}}<h3>{{.Title}} ({{.Total}})</h3><ul>{{template "list" .}}</ul>{{end}}{{end}}
+{{with .Rep.Relabeled}}<h2>Label changes since {{$.Rep.Baseline}}</h2><ul>{{range cap .}}<li>{{template "pr" .}}: {{.From}} → {{.Label}}</li>{{end}}{{with more . (len .)}}<li class="muted">… and {{.}} more</li>{{end}}</ul>{{end}}
+{{with .Opened}}<h2>Opened</h2><ul>{{template "list" .}}</ul>{{end}}
+{{with .Closed}}<h2>Closed / merged</h2><ul>{{range cap .PRs}}<li>{{template "pr" .}} <span class="muted">({{.State}})</span></li>{{end}}{{with more .PRs .Total}}<li class="muted">… and {{.}} more</li>{{end}}</ul>{{end}}
+{{with .Reopened}}<h2>Reopened</h2><ul>{{template "list" .}}</ul>{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone page.
+func HTML(rep Report) (string, error) {
+	list := func(prs []PR) *Group {
+		if len(prs) == 0 {
+			return nil
+		}
+		return &Group{PRs: prs, Total: len(prs)}
+	}
+	var b bytes.Buffer
+	err := htmlTemplate.Execute(&b, struct {
+		Rep                      Report
+		Glance                   []string
+		Opened, Closed, Reopened *Group
+	}{rep, glance(rep), list(rep.Opened), list(rep.Closed), list(rep.Reopened)})
+	if err != nil {
+		return "", fmt.Errorf("render report: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,263 @@
+// Package report builds the human-facing daily report: what ingest saw,
+// what got carded, what changed label, and what needs a maintainer, over a
+// time window. Everything is read from the data root; nothing calls the LLM.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/ingest"
+	"github.com/joshp123/github-triage/internal/inventory"
+	"github.com/joshp123/github-triage/internal/queue"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// MaxItems caps each list in the report; the rest is summarised as a count.
+const MaxItems = 15
+
+// PR is one line of the report.
+type PR struct {
+	Number  int
+	Title   string
+	URL     string
+	Author  string
+	State   string
+	Label   string
+	Summary string
+	// From is the previous label (relabels) or state (state changes).
+	From string
+}
+
+// Group is a labelled list of PRs, with the total before MaxItems applied.
+type Group struct {
+	Label string
+	Title string
+	PRs   []PR
+	Total int
+}
+
+type Report struct {
+	Repo  string
+	Since time.Time
+	Until time.Time
+
+	Opened   []PR
+	Closed   []PR // closed or merged; State says which
+	Reopened []PR
+	Updated  int
+	// NoEvents is set when ingest has never logged events, so the ingest
+	// section is unknown rather than empty.
+	NoEvents bool
+
+	// Carded groups map cards written in the window by label.
+	Carded     []Group
+	Maintainer int // maintainer cards written in the window
+
+	// Relabeled carries display names in From and Label.
+	Relabeled []PR
+	// Baseline is the snapshot label changes are measured against; empty
+	// when there is none from before the window.
+	Baseline string
+
+	CloseReady int
+	SweepTotal int
+
+	// NeedsHuman groups open PRs whose label the taxonomy marks needs_human.
+	NeedsHuman []Group
+}
+
+type rawPR struct {
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	State  string `json:"state"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// Build collects the report for [since, now] from the data root.
+func Build(cfg config.Config, tax taxonomy.Taxonomy, since time.Time, now time.Time) (Report, error) {
+	rep := Report{Repo: cfg.Repo, Since: since, Until: now}
+	// Raw snapshots of PRs that left the open list are not refetched, so
+	// open/closed comes from state.json.
+	state, err := ingest.LoadState(cfg.StatePath)
+	if err != nil {
+		return Report{}, err
+	}
+	raw := map[int]rawPR{}
+	lookup := func(pr int) rawPR {
+		if info, ok := raw[pr]; ok {
+			return info
+		}
+		info := rawPR{}
+		if path, err := cfg.PRFilePath(pr, "pr"); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				_ = json.Unmarshal(data, &info)
+			}
+		}
+		raw[pr] = info
+		return info
+	}
+	line := func(pr int) PR {
+		info := lookup(pr)
+		return PR{Number: pr, Title: info.Title, URL: info.URL, Author: info.Author.Login, State: state.StateOf(pr, info.State)}
+	}
+
+	// Ingest: one entry per PR, its last event in the window wins.
+	if _, err := os.Stat(cfg.EventsPath); errors.Is(err, os.ErrNotExist) {
+		rep.NoEvents = true
+	}
+	events, err := ingest.ReadEvents(cfg.EventsPath, since)
+	if err != nil {
+		return Report{}, err
+	}
+	last := map[int]ingest.Event{}
+	opened := map[int]bool{}
+	for _, event := range events {
+		if event.Time.After(now) {
+			continue
+		}
+		if event.Kind == ingest.EventNew {
+			opened[event.PR] = true
+		}
+		last[event.PR] = event
+	}
+	for pr, event := range last {
+		p := line(pr)
+		p.From = event.From
+		switch {
+		case opened[pr] && p.State != "closed" && p.State != "merged":
+			rep.Opened = append(rep.Opened, p)
+		case event.Kind == ingest.EventClosed || event.Kind == ingest.EventMerged:
+			if p.State == "" || p.State == "open" {
+				p.State = event.To
+			}
+			rep.Closed = append(rep.Closed, p)
+		case event.Kind == ingest.EventReopened:
+			rep.Reopened = append(rep.Reopened, p)
+		case opened[pr]:
+			rep.Closed = append(rep.Closed, p) // opened and closed within the window
+		default:
+			rep.Updated++
+		}
+	}
+	for _, list := range [][]PR{rep.Opened, rep.Closed, rep.Reopened} {
+		sortPRs(list)
+	}
+
+	// Cards written in the window, and open PRs that need a human.
+	records := []card.Record{}
+	if _, err := os.Stat(cfg.MapDir); err == nil {
+		records, err = card.ReadDir(cfg.MapDir)
+		if err != nil {
+			return Report{}, err
+		}
+	}
+	carded := map[string][]PR{}
+	waiting := map[string][]PR{}
+	for _, rec := range records {
+		p := line(rec.PR)
+		p.Label, p.Summary = rec.Label, rec.Summary
+		if p.Author == "" {
+			p.Author = rec.Author
+		}
+		if !rec.WrittenAt.Before(since) && !rec.WrittenAt.After(now) {
+			if rec.Maintainer {
+				rep.Maintainer++
+			} else {
+				carded[rec.Label] = append(carded[rec.Label], p)
+			}
+		}
+		if !rec.Maintainer && tax.NeedsHuman(rec.Label) && (p.State == "" || p.State == "open") {
+			waiting[rec.Label] = append(waiting[rec.Label], p)
+		}
+	}
+	rep.Carded = groups(tax, carded, nil)
+	rep.NeedsHuman = groups(tax, waiting, nil)
+
+	// Label changes since the last snapshot taken before the window.
+	dates, err := inventory.ListSnapshots(cfg.DataRoot)
+	if err != nil {
+		return Report{}, err
+	}
+	var baseline *inventory.Snapshot
+	for i := len(dates) - 1; i >= 0; i-- {
+		snap, err := inventory.LoadSnapshot(cfg.DataRoot, dates[i])
+		if err != nil {
+			return Report{}, err
+		}
+		if !snap.GeneratedAt.After(since) {
+			baseline = &snap
+			break
+		}
+	}
+	if baseline != nil && len(records) > 0 {
+		items, err := inventory.Build(cfg.DataRoot, tax)
+		if err != nil {
+			return Report{}, err
+		}
+		rep.Baseline = baseline.Name
+		diff, err := inventory.Compare(cfg.DataRoot, *baseline, inventory.Snapshot{Name: "now", Items: items})
+		if err != nil {
+			return Report{}, err
+		}
+		for _, r := range diff.Relabeled {
+			p := line(r.Item.PR)
+			p.Label, p.Summary, p.From = tax.Display(r.Item.Label), r.Item.Summary, tax.Display(r.From)
+			rep.Relabeled = append(rep.Relabeled, p)
+		}
+	}
+
+	if _, err := os.Stat(cfg.SweepDir); err == nil {
+		q, err := queue.BuildCloseQueue(cfg.SweepDir, tax)
+		if err != nil {
+			return Report{}, err
+		}
+		rep.CloseReady, rep.SweepTotal = q.CloseReady, q.Total
+	}
+	return rep, nil
+}
+
+// groups orders per-label lists by taxonomy order (only labels keep allows,
+// nil keeps all), newest PR first, capped at MaxItems.
+func groups(tax taxonomy.Taxonomy, byLabel map[string][]PR, keep func(taxonomy.Label) bool) []Group {
+	out := []Group{}
+	for _, label := range tax.Labels {
+		prs := byLabel[label.Name]
+		if len(prs) == 0 || (keep != nil && !keep(label)) {
+			continue
+		}
+		sortPRs(prs)
+		group := Group{Label: label.Name, Title: tax.Display(label.Name), Total: len(prs)}
+		if len(prs) > MaxItems {
+			prs = prs[:MaxItems]
+		}
+		group.PRs = prs
+		out = append(out, group)
+	}
+	return out
+}
+
+func sortPRs(prs []PR) {
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Number > prs[j].Number })
+}
+
+// Window describes the report span for headings, e.g. "last 24h".
+func (r Report) Window() string {
+	d := r.Until.Sub(r.Since).Round(time.Minute)
+	switch {
+	case d >= 48*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("last %dd", int(d/(24*time.Hour)))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("last %dh", int(d/time.Hour))
+	default:
+		return "since " + r.Since.UTC().Format("2006-01-02 15:04")
+	}
+}
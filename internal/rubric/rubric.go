@@ -0,0 +1,170 @@
+package rubric
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Every rubric.md starts with a version header; each version is also kept
+// under triage/rubric/vN.md so cards can be traced to the rubric they used.
+var headerRe = regexp.MustCompile(`^<!-- rubric v(\d+) `)
+
+var versionRe = regexp.MustCompile(`^v(\d+)\.md$`)
+
+func Path(root string) string {
+	return filepath.Join(root, "triage", "rubric.md")
+}
+
+func HistoryDir(root string) string {
+	return filepath.Join(root, "triage", "rubric")
+}
+
+// Validate checks the rubric has the sections the classifier prompts rely on:
+// a "### <label>" heading for every built-in label in labels. Labels the repo
+// added to its taxonomy only produce a warning when their section is missing.
+// A label heading matches on its first word, so "### good (rare)" is the good
+// section and "### goodness" is not.
+func Validate(body string, labels []string) ([]string, error) {
+	text := strings.TrimSpace(body)
+	if !strings.HasPrefix(text, "# Rubric") {
+		return nil, errors.New("rubric must start with '# Rubric'")
+	}
+	headings := map[string]bool{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "#") {
+			headings[line] = true
+			// "### good (extremely rare)" is the good section.
+			if fields := strings.Fields(line); len(fields) > 1 {
+				headings[fields[0]+" "+fields[1]] = true
+			}
+		}
+	}
+	for _, heading := range []string{"## Labels", "## Decision checklist"} {
+		if !headings[heading] {
+			return nil, fmt.Errorf("rubric is missing %q", heading)
+		}
+	}
+	builtin := map[string]bool{}
+	for _, name := range taxonomy.Default().Names() {
+		builtin[name] = true
+	}
+	warnings := []string{}
+	for _, label := range labels {
+		heading := "### " + label
+		switch {
+		case headings[heading]:
+		case builtin[label]:
+			return nil, fmt.Errorf("rubric is missing %q (label from the taxonomy)", heading)
+		default:
+			warnings = append(warnings, fmt.Sprintf("rubric has no %q section; the classifier only has the taxonomy description for %s", heading, label))
+		}
+	}
+	return warnings, nil
+}
+
+// Write validates body, stores it as the next version, and replaces
+// triage/rubric.md. source says where it came from (discover, docs/RUBRIC.md).
+// It returns the version and Validate's warnings.
+func Write(root string, body string, source string) (int, []string, error) {
+	body = strings.TrimSpace(stripHeader(body))
+	tax, err := taxonomy.Load(root)
+	if err != nil {
+		return 0, nil, err
+	}
+	warnings, err := Validate(body, tax.Names())
+	if err != nil {
+		return 0, nil, err
+	}
+	version, err := LatestVersion(root)
+	if err != nil {
+		return 0, nil, err
+	}
+	version++
+
+	header := fmt.Sprintf("<!-- rubric v%d source=%s generated=%s -->\n", version, source, time.Now().UTC().Format(time.RFC3339))
+	data := []byte(header + body + "\n")
+	if err := storage.WriteFileAtomic(filepath.Join(HistoryDir(root), fmt.Sprintf("v%d.md", version)), data, 0o644); err != nil {
+		return 0, nil, err
+	}
+	if err := storage.WriteFileAtomic(Path(root), data, 0o644); err != nil {
+		return 0, nil, err
+	}
+	return version, warnings, nil
+}
+
+// LatestVersion is the highest stored version, or 0 when none exist.
+func LatestVersion(root string) (int, error) {
+	entries, err := os.ReadDir(HistoryDir(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	latest := 0
+	for _, entry := range entries {
+		match := versionRe.FindStringSubmatch(entry.Name())
+		if len(match) != 2 {
+			continue
+		}
+		if num, err := strconv.Atoi(match[1]); err == nil && num > latest {
+			latest = num
+		}
+	}
+	return latest, nil
+}
+
+// CurrentVersion reads the version header of triage/rubric.md; 0 means the
+// file is missing or was written by hand.
+func CurrentVersion(root string) int {
+	data, err := os.ReadFile(Path(root))
+	if err != nil {
+		return 0
+	}
+	match := headerRe.FindStringSubmatch(string(data))
+	if len(match) != 2 {
+		return 0
+	}
+	num, _ := strconv.Atoi(match[1])
+	return num
+}
+
+func stripHeader(body string) string {
+	trimmed := strings.TrimLeft(body, "\n")
+	if headerRe.MatchString(trimmed) {
+		if idx := strings.Index(trimmed, "\n"); idx >= 0 {
+			return trimmed[idx+1:]
+		}
+		return ""
+	}
+	return body
+}
+
+// Stamp identifies the current rubric for card provenance as v<N>@<hash>,
+// where the hash covers the rubric body (not its version header). A
+// hand-written rubric without a header is v0; a missing one is "(none)".
+func Stamp(root string) string {
+	data, err := os.ReadFile(Path(root))
+	if err != nil {
+		return "(none)"
+	}
+	body := strings.TrimSpace(stripHeader(string(data)))
+	return fmt.Sprintf("v%d@%s", CurrentVersion(root), ShortHash([]byte(body)))
+}
+
+// ShortHash is the first 12 hex chars of the sha256 of data.
+func ShortHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
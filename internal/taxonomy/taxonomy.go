@@ -0,0 +1,221 @@
+package taxonomy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joshp123/github-triage/internal/config"
+)
+
+// Label is one classification bucket. Stages limits which per-PR stages may
+// assign it (empty = all); Closable labels feed the close queue. The
+// Fallback label (at most one) is what prompts tell the model to choose when
+// unsure. NeedsHuman labels mark PRs waiting on a maintainer: the report's
+// "Needs a human" section and aging's stale list.
+type Label struct {
+	Name        string   `toml:"name"`
+	Display     string   `toml:"display,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	Stages      []string `toml:"stages,omitempty"`
+	Closable    bool     `toml:"closable,omitempty"`
+	Fallback    bool     `toml:"fallback,omitempty"`
+	NeedsHuman  bool     `toml:"needs_human,omitempty"`
+}
+
+// Taxonomy is the ordered label set for a repo (see LoadWithSource).
+type Taxonomy struct {
+	Labels []Label `toml:"label"`
+}
+
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Default is the built-in taxonomy used when a repo has no taxonomy file.
+func Default() Taxonomy {
+	return Taxonomy{Labels: []Label{
+		{Name: "good", Description: "Small, targeted bugfix: minimal diff, clear repo-level alignment, evidence of a real bug or regression. Extremely rare.", Stages: []string{"map"}},
+		{Name: "needs-human", Description: "Security/safety/tool-policy/auth/provider changes or core runtime behavior with unclear repo-wide impact. Rare.", NeedsHuman: true},
+		{Name: "slop", Display: "low-signal", Description: "Low-value, misaligned, vague, or agent-generated PRs: docs-only changes, new features/integrations, config surface expansion, dependency upgrades, large/multi-topic PRs, and PRs whose title/body is mostly non-English or garbled.", Closable: true, Fallback: true},
+	}}
+}
+
+func Path(root string) string {
+	return filepath.Join(root, "triage", "taxonomy.toml")
+}
+
+// Load returns the taxonomy in effect for the data root root.
+func Load(root string) (Taxonomy, error) {
+	tax, _, err := LoadWithSource(root)
+	return tax, err
+}
+
+// LoadWithSource also reports where the taxonomy came from. The first of
+// these wins: triage/taxonomy.toml, a [taxonomy] table in the repo's
+// triage.toml, one in the user triage.toml, then Default ("built-in").
+func LoadWithSource(root string) (Taxonomy, string, error) {
+	path := Path(root)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		tax := Taxonomy{}
+		if _, err := toml.Decode(string(data), &tax); err != nil {
+			return Taxonomy{}, "", fmt.Errorf("parse %s: %w", path, err)
+		}
+		if err := tax.check(); err != nil {
+			return Taxonomy{}, "", fmt.Errorf("%s: %w", path, err)
+		}
+		return tax, path, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return Taxonomy{}, "", err
+	}
+
+	settings := []string{filepath.Join(root, "triage.toml")}
+	if user, err := config.UserSettingsPath(); err == nil {
+		settings = append(settings, user)
+	}
+	for _, path := range settings {
+		tax, ok, err := fromSettings(path)
+		if err != nil {
+			return Taxonomy{}, "", err
+		}
+		if ok {
+			return tax, path + " [taxonomy]", nil
+		}
+	}
+	return Default(), "built-in", nil
+}
+
+// fromSettings reads the [taxonomy] table of a triage.toml, if present.
+func fromSettings(path string) (Taxonomy, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Taxonomy{}, false, nil
+		}
+		return Taxonomy{}, false, err
+	}
+	var file struct {
+		Taxonomy *Taxonomy `toml:"taxonomy"`
+	}
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return Taxonomy{}, false, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if file.Taxonomy == nil {
+		return Taxonomy{}, false, nil
+	}
+	if err := file.Taxonomy.check(); err != nil {
+		return Taxonomy{}, false, fmt.Errorf("%s [taxonomy]: %w", path, err)
+	}
+	return *file.Taxonomy, true, nil
+}
+
+func (t Taxonomy) check() error {
+	if len(t.Labels) == 0 {
+		return errors.New("taxonomy defines no labels")
+	}
+	seen := map[string]bool{}
+	fallback := ""
+	for _, label := range t.Labels {
+		if !nameRe.MatchString(label.Name) {
+			return fmt.Errorf("invalid label name %q (want lowercase-kebab)", label.Name)
+		}
+		if seen[label.Name] {
+			return fmt.Errorf("duplicate label %q", label.Name)
+		}
+		seen[label.Name] = true
+		if label.Fallback {
+			if fallback != "" {
+				return fmt.Errorf("labels %q and %q are both the fallback (want at most one)", fallback, label.Name)
+			}
+			fallback = label.Name
+		}
+	}
+	return nil
+}
+
+// Encode renders t as taxonomy.toml.
+func (t Taxonomy) Encode() ([]byte, error) {
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(t); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+func (t Taxonomy) Find(name string) (Label, bool) {
+	for _, label := range t.Labels {
+		if label.Name == name {
+			return label, true
+		}
+	}
+	return Label{}, false
+}
+
+// Names lists every label in display order.
+func (t Taxonomy) Names() []string {
+	names := make([]string, 0, len(t.Labels))
+	for _, label := range t.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// ForStage lists the labels a stage may assign, in display order.
+func (t Taxonomy) ForStage(stage string) []Label {
+	out := []Label{}
+	for _, label := range t.Labels {
+		if label.allows(stage) {
+			out = append(out, label)
+		}
+	}
+	return out
+}
+
+func (l Label) allows(stage string) bool {
+	if len(l.Stages) == 0 || stage == "" {
+		return true
+	}
+	for _, s := range l.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks label against the taxonomy and, when stage is set, the
+// stage's allowed labels.
+func (t Taxonomy) Validate(label string, stage string) error {
+	allowed := t.ForStage(stage)
+	names := make([]string, 0, len(allowed))
+	for _, l := range allowed {
+		if l.Name == label {
+			return nil
+		}
+		names = append(names, l.Name)
+	}
+	return fmt.Errorf("invalid --label %q (want %s)", label, strings.Join(names, "|"))
+}
+
+// Display is the human-facing name for label (defaults to the name).
+func (t Taxonomy) Display(label string) string {
+	if l, ok := t.Find(label); ok && strings.TrimSpace(l.Display) != "" {
+		return l.Display
+	}
+	return label
+}
+
+func (t Taxonomy) Closable(label string) bool {
+	l, ok := t.Find(label)
+	return ok && l.Closable
+}
+
+// NeedsHuman reports whether open PRs with label wait on a maintainer.
+func (t Taxonomy) NeedsHuman(label string) bool {
+	l, ok := t.Find(label)
+	return ok && l.NeedsHuman
+}
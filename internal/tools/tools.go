@@ -0,0 +1,593 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/gh"
+	"github.com/joshp123/github-triage/internal/injection"
+	"github.com/joshp123/github-triage/internal/inventory"
+	"github.com/joshp123/github-triage/internal/policy"
+	"github.com/joshp123/github-triage/internal/release"
+	"github.com/joshp123/github-triage/internal/rubric"
+	"github.com/joshp123/github-triage/internal/storage"
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+// Tool is a typed function exposed to the model. Every backend registers
+// Parameters (a JSON schema) with the model as a native tool and runs
+// Handler in the triage process; `triage tool` calls the same handler from a
+// shell (replay recordings, humans).
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+	Handler     func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// Context is what a tool handler may touch: the repo config (and so the
+// data root), the card directory for the current stage (relative to the
+// data root), the stage policy, where refused attempts are recorded, and the
+// provenance stamped onto cards. PR binds a per-PR session to its PR: the
+// PR tools refuse any other (0 leaves them unbound, e.g. release notes).
+type Context struct {
+	Config     config.Config
+	CardDir    string
+	Policy     policy.Policy
+	BlockedLog string
+	Provenance card.Provenance
+	PR         int
+}
+
+// Set is the tools one session may call.
+type Set struct {
+	Context Context
+	Tools   []Tool
+}
+
+const maxReadBytes = 256 * 1024
+
+var Names = []string{"read_file", "read_pr_file", "write_card", "write_partial", "write_inventory", "write_release_notes", "write_injection", "write_rubric", "run_command"}
+
+func build(c Context, name string) (Tool, bool) {
+	switch name {
+	case "read_file":
+		return ReadFile(c), true
+	case "read_pr_file":
+		return ReadPRFile(c), true
+	case "write_card":
+		return WriteCard(c), true
+	case "write_partial":
+		return WritePartial(c), true
+	case "write_inventory":
+		return WriteInventory(c), true
+	case "write_release_notes":
+		return WriteReleaseNotes(c), true
+	case "write_injection":
+		return WriteInjection(c), true
+	case "write_rubric":
+		return WriteRubric(c), true
+	case "run_command":
+		return RunCommand(c), true
+	default:
+		return Tool{}, false
+	}
+}
+
+// ForStage returns the tools the stage policy allows.
+func ForStage(c Context) Set {
+	set := Set{Context: c}
+	for _, name := range c.Policy.Tools {
+		if tool, ok := build(c, name); ok {
+			set.Tools = append(set.Tools, tool)
+		}
+	}
+	return set
+}
+
+// Lookup returns one tool for `triage tool`, refusing (and recording)
+// tools outside the stage policy.
+func Lookup(c Context, name string) (Tool, error) {
+	if err := c.Policy.AllowsTool(name); err != nil {
+		c.block("tool "+name, err)
+		return Tool{}, err
+	}
+	tool, ok := build(c, name)
+	if !ok {
+		return Tool{}, fmt.Errorf("unknown tool %q (want %s)", name, strings.Join(Names, "|"))
+	}
+	return tool, nil
+}
+
+func (s Set) Find(name string) (Tool, bool) {
+	for _, tool := range s.Tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// Call runs one tool call and returns the text fed back to the model.
+// Errors are returned as text so the model can correct its arguments.
+func (s Set) Call(ctx context.Context, name string, args json.RawMessage) string {
+	tool, ok := s.Find(name)
+	if !ok {
+		err := s.Context.Policy.AllowsTool(name)
+		if err == nil {
+			err = fmt.Errorf("unknown tool %q", name)
+		}
+		s.Context.block("tool "+name, err)
+		return "error: " + err.Error()
+	}
+	if len(strings.TrimSpace(string(args))) == 0 {
+		args = json.RawMessage("{}")
+	}
+	out, err := tool.Handler(ctx, args)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return out
+}
+
+func (c Context) block(attempt string, reason error) {
+	_ = policy.Record(c.BlockedLog, c.Policy.Stage, attempt, reason)
+}
+
+// checkPR refuses (and records) a tool call for a PR other than the
+// session's. PR text is adversarial: a session for one PR must not read or
+// rewrite another's files.
+func (c Context) checkPR(tool string, pr int) error {
+	if c.PR == 0 || pr == c.PR {
+		return nil
+	}
+	err := fmt.Errorf("this session is for PR %d, not PR %d", c.PR, pr)
+	c.block(fmt.Sprintf("tool %s pr=%d", tool, pr), err)
+	return err
+}
+
+func decode(args json.RawMessage, out any) error {
+	if err := json.Unmarshal(args, out); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func ReadFile(c Context) Tool {
+	return Tool{
+		Name:        "read_file",
+		Description: "Read a file, or list a directory, under triage/ or repo/ (relative to the working directory).",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "path": {"type": "string", "description": "Relative path, e.g. triage/rubric.md or triage/map"}
+  },
+  "required": ["path"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Path string `json:"path"`
+			}
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			path, err := resolveReadPath(c.Config.DataRoot, in.Path)
+			if err != nil {
+				return "", err
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return "", err
+			}
+			if info.IsDir() {
+				return listDir(path)
+			}
+			return readCapped(path)
+		},
+	}
+}
+
+func ReadPRFile(c Context) Tool {
+	return Tool{
+		Name:        "read_pr_file",
+		Description: "Read one cached file for a PR. kind=diff fetches and caches the diff on first use; kind=injection is the injection pre-pass verdict.",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "pr": {"type": "integer"},
+    "kind": {"type": "string", "enum": ["pr", "files", "meta", "comments", "reviews", "review-comments", "diff", "injection"]}
+  },
+  "required": ["pr", "kind"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				PR   int    `json:"pr"`
+				Kind string `json:"kind"`
+			}
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			if in.PR <= 0 {
+				return "", errors.New("pr must be > 0")
+			}
+			if err := c.checkPR("read_pr_file", in.PR); err != nil {
+				return "", err
+			}
+			path, err := c.Config.PRFilePath(in.PR, in.Kind)
+			if err != nil {
+				return "", err
+			}
+			if in.Kind == "diff" {
+				if err := ensureDiff(ctx, c.Config, in.PR, path); err != nil {
+					return "", err
+				}
+			}
+			if _, err := os.Stat(path); err != nil {
+				return "", fmt.Errorf("%s not cached for PR %d", in.Kind, in.PR)
+			}
+			return readCapped(path)
+		},
+	}
+}
+
+func ensureDiff(ctx context.Context, cfg config.Config, pr int, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	out, err := gh.Run(ctx, "pr", "diff", fmt.Sprintf("%d", pr), "--repo", cfg.Repo)
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, out, 0o644)
+}
+
+// CardDir resolves a write_card directory (relative to the data root) for
+// stage, defaulting to the stage's card dir. Cards may only land in
+// triage/map, triage/sweep or an eval run's cards dir.
+func CardDir(cfg config.Config, stage string, dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		if stage == "sweep" {
+			return cfg.SweepDir, nil
+		}
+		return cfg.MapDir, nil
+	}
+	path := filepath.Clean(dir)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.DataRoot, path)
+	}
+	if path == cfg.MapDir || path == cfg.SweepDir {
+		return path, nil
+	}
+	if rel, err := filepath.Rel(cfg.EvalDir, path); err == nil {
+		parts := strings.Split(rel, string(filepath.Separator))
+		if len(parts) == 2 && parts[0] != ".." && parts[0] != "." && parts[1] == "cards" {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("card dir %s is not triage/map, triage/sweep or triage/eval/<run>/cards", dir)
+}
+
+func WriteCard(c Context) Tool {
+	return Tool{
+		Name:        "write_card",
+		Description: "Write the classification card for one PR. For maintainer PRs only pr and author are needed.",
+		Parameters: json.RawMessage(fmt.Sprintf(`{
+  "type": "object",
+  "properties": {
+    "pr": {"type": "integer"},
+    "author": {"type": "string", "description": "PR author login"},
+    "maintainer": {"type": "string", "enum": ["auto", "yes", "no"], "description": "Leave as auto; the CLI decides from triage/maintainers.txt"},
+    "label": {"type": "string", "enum": %s},
+    "summary": {"type": "string", "description": "One-line summary"},
+    "evidence": {"type": "array", "items": {"type": "string"}, "description": "\"quote\" (source) items"},
+    "notes": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["pr", "author"]
+}`, labelEnum(c, c.Policy.Stage))),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in card.Input
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			if err := c.checkPR("write_card", in.PR); err != nil {
+				return "", err
+			}
+			if in.Maintainer == "" {
+				in.Maintainer = "auto"
+			}
+			if label := strings.TrimSpace(in.Label); label != "" {
+				tax, err := taxonomy.Load(c.Config.DataRoot)
+				if err != nil {
+					return "", err
+				}
+				if err := tax.Validate(label, c.Policy.Stage); err != nil {
+					return "", err
+				}
+			}
+			cardDir, err := CardDir(c.Config, c.Policy.Stage, c.CardDir)
+			if err != nil {
+				return "", err
+			}
+			path, err := card.Write(c.Config.DataRoot, cardDir, in, c.Provenance)
+			if err != nil {
+				return "", err
+			}
+			return "wrote " + relPath(c.Config.DataRoot, path), nil
+		},
+	}
+}
+
+// labelEnum is the JSON label enum for stage from the repo taxonomy. A
+// taxonomy that fails to load falls back to the default here; the write
+// handlers report the load error.
+func labelEnum(c Context, stage string) string {
+	tax, err := taxonomy.Load(c.Config.DataRoot)
+	if err != nil {
+		tax = taxonomy.Default()
+	}
+	names := []string{}
+	for _, label := range tax.ForStage(stage) {
+		names = append(names, label.Name)
+	}
+	data, _ := json.Marshal(names)
+	return string(data)
+}
+
+func WritePartial(c Context) Tool {
+	return Tool{
+		Name:        "write_partial",
+		Description: "Write the summary of one reduce batch (triage/reduce/partials/batch-NNN.md).",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "batch": {"type": "integer", "description": "Batch number from the input file"},
+    "summary": {"type": "string", "description": "Markdown bullets summarising the batch. No headings."}
+  },
+  "required": ["batch", "summary"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Batch   int    `json:"batch"`
+				Summary string `json:"summary"`
+			}
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			if in.Batch <= 0 {
+				return "", errors.New("batch must be > 0")
+			}
+			path, err := inventory.WritePartial(c.Config.DataRoot, in.Batch, in.Summary)
+			if err != nil {
+				return "", err
+			}
+			return "wrote " + relPath(c.Config.DataRoot, path), nil
+		},
+	}
+}
+
+func WriteInventory(c Context) Tool {
+	return Tool{
+		Name:        "write_inventory",
+		Description: "Write the inventory snapshot (triage/reduce/current.md). The CLI builds counts and grouped items from triage/map cards; pass only the narrative overview.",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "overview": {"type": "string", "description": "Markdown overview of the pile: themes, clusters, notable PRs. No headings."}
+  },
+  "required": ["overview"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Overview string `json:"overview"`
+			}
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(in.Overview) == "" {
+				return "", errors.New("overview is required")
+			}
+			paths, count, err := inventory.Write(c.Config.DataRoot, in.Overview, inventory.DefaultFormats)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("wrote %s (%d items, overview)", relPath(c.Config.DataRoot, paths[0]), count), nil
+		},
+	}
+}
+
+func WriteReleaseNotes(c Context) Tool {
+	return Tool{
+		Name:        "write_release_notes",
+		Description: "Write the release notes draft (triage/release-notes/draft.md) from the PRs in triage/release-notes/input.json. The CLI adds PR links, authors, and the PRs left out.",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "intro": {"type": "string", "description": "Optional one or two sentence summary of the release"},
+    "sections": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "title": {"type": "string", "description": "Category, e.g. Features, Fixes, Docs"},
+          "entries": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "prs": {"type": "array", "items": {"type": "integer"}, "description": "PR numbers from the input this line covers"},
+                "text": {"type": "string", "description": "One user-facing changelog line, no PR numbers"}
+              },
+              "required": ["prs", "text"]
+            }
+          }
+        },
+        "required": ["title", "entries"]
+      }
+    }
+  },
+  "required": ["sections"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in release.Notes
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			path, err := release.Write(c.Config.DataRoot, in)
+			if err != nil {
+				return "", err
+			}
+			return "wrote " + relPath(c.Config.DataRoot, path), nil
+		},
+	}
+}
+
+func WriteInjection(c Context) Tool {
+	return Tool{
+		Name:        "write_injection",
+		Description: "Write the prompt-injection verdict for one PR (triage/injection/pr-N.injection.json, plus a Markdown view).",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "pr": {"type": "integer"},
+    "verdict": {"type": "string", "enum": ["suspected", "none"]},
+    "evidence": {"type": "array", "items": {"type": "string"}, "description": "\"quote\" (source) items, checked against the PR's cached files; at least one must verify when suspected"},
+    "notes": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["pr", "verdict"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in injection.Verdict
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			if err := c.checkPR("write_injection", in.PR); err != nil {
+				return "", err
+			}
+			path, err := injection.Write(c.Config.DataRoot, in)
+			if err != nil {
+				return "", err
+			}
+			return "wrote " + relPath(c.Config.DataRoot, path), nil
+		},
+	}
+}
+
+func WriteRubric(c Context) Tool {
+	return Tool{
+		Name:        "write_rubric",
+		Description: "Write the next version of triage/rubric.md. content is the full Markdown rubric.",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "content": {"type": "string", "description": "Markdown starting with '# Rubric'"}
+  },
+  "required": ["content"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Content string `json:"content"`
+			}
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			version, warnings, err := rubric.Write(c.Config.DataRoot, in.Content, "discover")
+			if err != nil {
+				return "", err
+			}
+			out := fmt.Sprintf("wrote triage/rubric.md (v%d)", version)
+			for _, warning := range warnings {
+				out += "\nwarning: " + warning
+			}
+			return out, nil
+		},
+	}
+}
+
+func resolveReadPath(root string, rel string) (string, error) {
+	rel = filepath.Clean(strings.TrimSpace(rel))
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q must be relative to the working directory", rel)
+	}
+	top := strings.SplitN(rel, string(filepath.Separator), 2)[0]
+	if top != "triage" && top != "repo" {
+		return "", errors.New("only triage/ and repo/ are readable")
+	}
+	return filepath.Join(root, rel), nil
+}
+
+func readCapped(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxReadBytes {
+		return string(data[:maxReadBytes]) + "\n[truncated]", nil
+	}
+	return string(data), nil
+}
+
+func listDir(path string) (string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n"), nil
+}
+
+func relPath(root string, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func RunCommand(c Context) Tool {
+	return Tool{
+		Name:        "run_command",
+		Description: "Run a read-only command without a shell: `gh api <path>` (GET only) or `git show|diff|log` inside repo/.",
+		Parameters: json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "argv": {"type": "array", "items": {"type": "string"}, "description": "e.g. [\"gh\", \"api\", \"/repos/org/name/pulls/123/reviews\"]"}
+  },
+  "required": ["argv"]
+}`),
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Argv []string `json:"argv"`
+			}
+			if err := decode(args, &in); err != nil {
+				return "", err
+			}
+			if err := c.Policy.CheckCommand(in.Argv); err != nil {
+				c.block(strings.Join(in.Argv, " "), err)
+				return "", err
+			}
+			out, err := policy.Exec(ctx, c.Config, in.Argv)
+			if len(out) > maxReadBytes {
+				out = append(out[:maxReadBytes], []byte("\n[truncated]")...)
+			}
+			if err != nil {
+				return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+			}
+			return string(out), nil
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshp123/github-triage/internal/card"
+	"github.com/joshp123/github-triage/internal/config"
+	"github.com/joshp123/github-triage/internal/injection"
+	"github.com/joshp123/github-triage/internal/policy"
+	"github.com/joshp123/github-triage/internal/storage"
+)
+
+// setup builds a data root with raw snapshots for PRs 1 and 2.
+func setup(t *testing.T) config.Config {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := config.FromDataRoot(filepath.Join(t.TempDir(), "o", "r"))
+	if err := cfg.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+	for _, pr := range []int{1, 2} {
+		raw := map[string]any{
+			"number": pr,
+			"state":  "OPEN",
+			"title":  "Add widgets",
+			"body":   "This adds widget support for dashboards.",
+			"author": map[string]string{"login": "bob"},
+		}
+		if err := storage.WriteJSONAtomic(cfg.RawPRPath(pr), raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfg
+}
+
+func TestSessionPR(t *testing.T) {
+	cardArgs := func(pr int) map[string]any {
+		return map[string]any{
+			"pr":       pr,
+			"author":   "bob",
+			"label":    "slop",
+			"summary":  "s",
+			"evidence": []string{`"widget support for dashboards" (raw)`},
+			"notes":    []string{"close-ready: yes spam"},
+		}
+	}
+	cases := []struct {
+		name    string
+		stage   string
+		session int
+		tool    string
+		args    map[string]any
+		wantErr bool
+		written string // file that must exist after the call
+	}{
+		{name: "read own PR", stage: "sweep", session: 1, tool: "read_pr_file", args: map[string]any{"pr": 1, "kind": "pr"}},
+		{name: "read another PR", stage: "sweep", session: 1, tool: "read_pr_file", args: map[string]any{"pr": 2, "kind": "pr"}, wantErr: true},
+		{name: "write own card", stage: "sweep", session: 1, tool: "write_card", args: cardArgs(1), written: "triage/sweep/pr-1.card.json"},
+		{name: "write another PR's card", stage: "sweep", session: 1, tool: "write_card", args: cardArgs(2), wantErr: true},
+		{name: "write own verdict", stage: "injection", session: 2, tool: "write_injection", args: map[string]any{"pr": 2, "verdict": "none"}, written: "triage/injection/pr-2.injection.json"},
+		{name: "write another PR's verdict", stage: "injection", session: 2, tool: "write_injection", args: map[string]any{"pr": 1, "verdict": "none"}, wantErr: true},
+		{name: "unbound session reads any PR", stage: "release-notes", tool: "read_pr_file", args: map[string]any{"pr": 2, "kind": "pr"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := setup(t)
+			blockedLog := filepath.Join(cfg.TriageDir, "test.blocked.jsonl")
+			c := Context{Config: cfg, Policy: policy.ForStage(tc.stage), BlockedLog: blockedLog, PR: tc.session}
+			tool, err := Lookup(c, tc.tool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args, _ := json.Marshal(tc.args)
+			_, err = tool.Handler(context.Background(), args)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			blocked, _ := policy.ReadBlocked(blockedLog)
+			if tc.wantErr {
+				if !strings.Contains(err.Error(), "this session is for PR") {
+					t.Errorf("err = %v, want a session PR refusal", err)
+				}
+				if len(blocked) != 1 {
+					t.Errorf("blocked log has %d entries, want 1", len(blocked))
+				}
+				other := tc.args["pr"].(int)
+				if _, err := card.Read(card.JSONPath(cfg.SweepDir, other)); err == nil {
+					t.Errorf("card for PR %d was written", other)
+				}
+				if _, err := injection.Read(cfg.DataRoot, other); err == nil {
+					t.Errorf("verdict for PR %d was written", other)
+				}
+				return
+			}
+			if len(blocked) != 0 {
+				t.Errorf("blocked log = %v, want empty", blocked)
+			}
+			if tc.written != "" {
+				if _, err := os.Stat(filepath.Join(cfg.DataRoot, tc.written)); err != nil {
+					t.Errorf("%s not written: %v", tc.written, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package prompts embeds the default stage prompts so the binary works
+// outside a checkout. Files with the same relative name in an override
+// directory (--prompt-dir, then <data-root>/triage/prompts) win. Prompts are
+// text/templates rendered with the repo context in Data.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/joshp123/github-triage/internal/taxonomy"
+)
+
+//go:embed *.md tools/*.md
+var FS embed.FS
+
+// Read returns the prompt at name (slash-separated, e.g. "map.md" or
+// "tools/pi.md") and where it came from: the first override dir that has
+// it, else "embedded".
+func Read(name string, dirs ...string) ([]byte, string, error) {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return data, path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("read prompt %s: %w", path, err)
+		}
+	}
+	data, err := FS.ReadFile(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("prompt %s not found", name)
+	}
+	return data, "embedded", nil
+}
+
+// Names lists every embedded prompt.
+func Names() ([]string, error) {
+	names := []string{}
+	err := fs.WalkDir(FS, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".md" {
+			names = append(names, path)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// Data is the repo context prompt templates are rendered with.
+type Data struct {
+	Repo        string
+	Org         string
+	Name        string
+	Project     string
+	Description string
+	Rules       []string
+	RubricPath  string
+	// Labels are the stage's label names in display order; LabelDefs carry
+	// their descriptions from the taxonomy. Fallback is the label to choose
+	// when unsure ("" when the taxonomy marks none).
+	Labels    []string
+	LabelDefs []taxonomy.Label
+	Fallback  string
+	Stage     string
+	Tools     []string
+}
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Render executes a prompt as a text/template; a typo in an override fails
+// here rather than reaching the model.
+func Render(name string, text []byte, data Data) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", name, err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("render prompt %s: %w", name, err)
+	}
+	return b.Bytes(), nil
+}